@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE bundle_components (
+    id INTEGER PRIMARY KEY,
+    bundle_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    quantity REAL NOT NULL DEFAULT 1,
+    FOREIGN KEY(bundle_id) REFERENCES products(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+    FOREIGN KEY(product_id) REFERENCES products(id)
+    ON DELETE RESTRICT
+    ON UPDATE CASCADE,
+    CHECK (bundle_id != product_id)
+);
+-- +goose StatementEnd
@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE vat_rates (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE vat_rate_periods (
+    id INTEGER PRIMARY KEY,
+    vat_rate_id INTEGER NOT NULL,
+    rate REAL NOT NULL,
+    valid_from INTEGER NOT NULL,
+    FOREIGN KEY(vat_rate_id) REFERENCES vat_rates(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+    UNIQUE (vat_rate_id, valid_from)
+);
+
+ALTER TABLE categories ADD COLUMN dine_in_vat_rate_id INTEGER REFERENCES vat_rates(id);
+ALTER TABLE categories ADD COLUMN takeaway_vat_rate_id INTEGER REFERENCES vat_rates(id);
+
+INSERT INTO vat_rates(id, name)
+values
+    (1, "Standard"),
+    (2, "Reduced"),
+    (3, "Restaurant food")
+;
+
+-- valid_from is a unix timestamp (midnight UTC of the first day the rate applies)
+INSERT INTO vat_rate_periods(vat_rate_id, rate, valid_from)
+values
+    (1, 19, 1167609600), -- 2007-01-01
+    (1, 16, 1593561600), -- 2020-07-01
+    (1, 19, 1609459200), -- 2021-01-01
+    (2, 7, 1167609600),  -- 2007-01-01
+    (2, 5, 1593561600),  -- 2020-07-01
+    (2, 7, 1609459200),  -- 2021-01-01
+    (3, 19, 1167609600), -- 2007-01-01
+    (3, 5, 1593561600),  -- 2020-07-01
+    (3, 7, 1609459200),  -- 2021-01-01
+    (3, 19, 1704067200), -- 2024-01-01
+    (3, 7, 1767225600)   -- 2026-01-01
+;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE sales_channels (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    vat_channel TEXT NOT NULL DEFAULT 'dine_in',
+    commission_percent REAL NOT NULL DEFAULT 0,
+    fixed_fee REAL NOT NULL DEFAULT 0,
+    payment_fee_percent REAL NOT NULL DEFAULT 0,
+    CHECK (vat_channel IN ('dine_in', 'takeaway'))
+);
+
+CREATE TABLE product_channel_prices (
+    product_id INTEGER NOT NULL,
+    channel_id INTEGER NOT NULL,
+    price REAL NOT NULL,
+    PRIMARY KEY (product_id, channel_id),
+    FOREIGN KEY(product_id) REFERENCES products(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+    FOREIGN KEY(channel_id) REFERENCES sales_channels(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE
+);
+
+INSERT INTO sales_channels(id, name, vat_channel)
+values
+    (1, "Dine-in", "dine_in"),
+    (2, "Takeaway", "takeaway")
+;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products ADD COLUMN prep_minutes REAL NOT NULL DEFAULT 0;
+
+CREATE TABLE settings (
+    key TEXT PRIMARY KEY,
+    value TEXT NOT NULL
+);
+
+INSERT INTO settings(key, value)
+values
+    ("labor_rate", "0"),
+    ("cost_basis", "ingredients")
+;
+
+-- overhead rules without a category apply to every product
+CREATE TABLE overhead_rules (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    amount REAL NOT NULL,
+    category_id INTEGER,
+    FOREIGN KEY(category_id) REFERENCES categories(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+    CHECK (kind IN ('fixed', 'percent'))
+);
+-- +goose StatementEnd
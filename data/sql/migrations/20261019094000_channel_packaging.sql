@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- packaging items are ordinary ingredients, so they are priced through ingredient_prices
+CREATE TABLE product_channel_packaging (
+    id INTEGER PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    channel_id INTEGER NOT NULL,
+    ingredient_id INTEGER NOT NULL,
+    quantity REAL NOT NULL,
+    unit_id INTEGER NOT NULL,
+    FOREIGN KEY(product_id) REFERENCES products(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+    FOREIGN KEY(channel_id) REFERENCES sales_channels(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+    FOREIGN KEY(ingredient_id) REFERENCES ingredients(id)
+    ON DELETE restrict
+    ON UPDATE CASCADE,
+    FOREIGN KEY(unit_id) REFERENCES units(id)
+    ON DELETE restrict
+    ON UPDATE CASCADE
+);
+-- +goose StatementEnd
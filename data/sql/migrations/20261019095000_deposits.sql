@@ -0,0 +1,8 @@
+-- +goose Up
+-- +goose StatementBegin
+-- deposit per pack, the price of the pack is stored without it
+ALTER TABLE ingredient_prices ADD COLUMN deposit REAL NOT NULL DEFAULT 0;
+
+-- deposit charged on top of the price of a sold product
+ALTER TABLE products ADD COLUMN deposit REAL NOT NULL DEFAULT 0;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- abv in % vol, NULL for ingredients that do not add to the volume of a drink
+-- (garnish, solids). Water and ice have an abv of 0.
+ALTER TABLE ingredients ADD COLUMN abv REAL;
+-- set if the ingredient price does not include the spirits duty yet
+ALTER TABLE ingredients ADD COLUMN duty_excluded BOOLEAN NOT NULL DEFAULT 0;
+
+-- spirits duty in € per liter of pure alcohol
+INSERT INTO settings(key, value)
+values
+    ("spirits_duty_rate", "13.03"),
+    ("standard_drink_grams", "10")
+;
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the 14 allergens that have to be declared in the EU, code is the letter
+-- commonly printed on menus
+CREATE TABLE allergens (
+    id INTEGER PRIMARY KEY,
+    code TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL
+);
+
+INSERT INTO allergens(id, code, name)
+values
+    (1, "A", "Gluten"),
+    (2, "B", "Crustaceans"),
+    (3, "C", "Eggs"),
+    (4, "D", "Fish"),
+    (5, "E", "Peanuts"),
+    (6, "F", "Soybeans"),
+    (7, "G", "Milk"),
+    (8, "H", "Nuts"),
+    (9, "L", "Celery"),
+    (10, "M", "Mustard"),
+    (11, "N", "Sesame"),
+    (12, "O", "Sulphites"),
+    (13, "P", "Lupin"),
+    (14, "R", "Molluscs")
+;
+
+CREATE TABLE ingredient_allergens (
+    ingredient_id INTEGER NOT NULL,
+    allergen_id INTEGER NOT NULL,
+    PRIMARY KEY(ingredient_id, allergen_id),
+    FOREIGN KEY(ingredient_id) REFERENCES ingredients(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+    FOREIGN KEY(allergen_id) REFERENCES allergens(id)
+    ON DELETE RESTRICT
+    ON UPDATE CASCADE
+);
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- nutrition values per 100 g or 100 ml of an ingredient
+CREATE TABLE ingredient_nutrition (
+    ingredient_id INTEGER PRIMARY KEY,
+    kcal REAL NOT NULL DEFAULT 0,
+    fat REAL NOT NULL DEFAULT 0,
+    carbs REAL NOT NULL DEFAULT 0,
+    sugar REAL NOT NULL DEFAULT 0,
+    protein REAL NOT NULL DEFAULT 0,
+    salt REAL NOT NULL DEFAULT 0,
+    FOREIGN KEY(ingredient_id) REFERENCES ingredients(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE
+);
+
+-- number of servings one product yields, used for the nutrition per serving
+ALTER TABLE products ADD COLUMN servings REAL NOT NULL DEFAULT 1;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE product_recipes (
+    product_id INTEGER PRIMARY KEY,
+    notes TEXT NOT NULL DEFAULT '',
+    glassware TEXT NOT NULL DEFAULT '',
+    garnish TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY(product_id) REFERENCES products(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE
+);
+
+CREATE TABLE recipe_steps (
+    id INTEGER PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    FOREIGN KEY(product_id) REFERENCES products(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE
+);
+
+-- the images are stored on disk, file_name is relative to the photo directory
+CREATE TABLE product_photos (
+    id INTEGER PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    file_name TEXT NOT NULL UNIQUE,
+    content_type TEXT NOT NULL,
+    time_stamp INTEGER NOT NULL,
+    FOREIGN KEY(product_id) REFERENCES products(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE
+);
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- scenarios hold hypothetical ingredient prices, they are never part of the
+-- cost cache until they are committed as real prices
+CREATE TABLE scenarios (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    time_stamp INTEGER NOT NULL DEFAULT ( unixepoch('now') ),
+    committed_at INTEGER
+);
+
+-- a percent override changes the current price, a price override replaces
+-- the price per base unit
+CREATE TABLE scenario_overrides (
+    id INTEGER PRIMARY KEY,
+    scenario_id INTEGER NOT NULL,
+    ingredient_id INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    amount REAL NOT NULL,
+    FOREIGN KEY(scenario_id) REFERENCES scenarios(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+    FOREIGN KEY(ingredient_id) REFERENCES ingredients(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+    UNIQUE(scenario_id, ingredient_id),
+    CHECK (kind IN ('percent', 'price'))
+);
+-- +goose StatementEnd
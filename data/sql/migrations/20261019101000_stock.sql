@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a delivery of an ingredient, price is what was paid for the quantity
+-- including the deposit
+CREATE TABLE goods_receipts (
+    id INTEGER PRIMARY KEY,
+    ingredient_id INTEGER NOT NULL,
+    quantity REAL NOT NULL,
+    unit_id INTEGER NOT NULL,
+    price REAL NOT NULL,
+    deposit REAL NOT NULL DEFAULT 0,
+    supplier TEXT NOT NULL DEFAULT '',
+    received_at INTEGER NOT NULL,
+    FOREIGN KEY(ingredient_id) REFERENCES ingredients(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+    FOREIGN KEY(unit_id) REFERENCES units(id)
+);
+
+-- quantity on hand in the base unit of the ingredient
+CREATE TABLE stock_levels (
+    ingredient_id INTEGER PRIMARY KEY,
+    quantity REAL NOT NULL DEFAULT 0,
+    FOREIGN KEY(ingredient_id) REFERENCES ingredients(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE
+);
+
+INSERT INTO settings(key, value)
+values
+    ('stock_valuation', 'latest')
+;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- finishing a stocktake sets the stock levels to the counted quantities
+CREATE TABLE stocktakes (
+    id INTEGER PRIMARY KEY,
+    counted_at INTEGER NOT NULL,
+    finished_at INTEGER
+);
+
+-- expected is the stock level in base units when the stocktake was finished
+CREATE TABLE stocktake_counts (
+    id INTEGER PRIMARY KEY,
+    stocktake_id INTEGER NOT NULL,
+    ingredient_id INTEGER NOT NULL,
+    quantity REAL NOT NULL,
+    unit_id INTEGER NOT NULL,
+    expected REAL,
+    FOREIGN KEY(stocktake_id) REFERENCES stocktakes(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+    FOREIGN KEY(ingredient_id) REFERENCES ingredients(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+    FOREIGN KEY(unit_id) REFERENCES units(id),
+    UNIQUE(stocktake_id, ingredient_id)
+);
+-- +goose StatementEnd
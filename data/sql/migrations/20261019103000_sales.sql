@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+-- sold_on is the start of the day in UTC, revenue is the gross revenue if
+-- the source knows it
+CREATE TABLE sales (
+    id INTEGER PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    sold_on INTEGER NOT NULL,
+    shift TEXT NOT NULL DEFAULT '',
+    source TEXT NOT NULL,
+    quantity REAL NOT NULL,
+    revenue REAL,
+    FOREIGN KEY(product_id) REFERENCES products(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+    UNIQUE(product_id, sold_on, shift, source),
+    CHECK (source IN ('pos', 'manual'))
+);
+
+-- item is the name or PLU the POS exports, items without a product are
+-- left out of imports until they are mapped
+CREATE TABLE pos_items (
+    id INTEGER PRIMARY KEY,
+    item TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL DEFAULT '',
+    product_id INTEGER,
+    FOREIGN KEY(product_id) REFERENCES products(id)
+    ON DELETE SET NULL
+    ON UPDATE CASCADE
+);
+
+INSERT INTO settings(key, value)
+values
+    ('pos_delimiter', ','),
+    ('pos_date_format', 'YYYY-MM-DD'),
+    ('pos_column_date', 'date'),
+    ('pos_column_item', 'plu'),
+    ('pos_column_name', 'name'),
+    ('pos_column_quantity', 'quantity'),
+    ('pos_column_revenue', 'revenue')
+;
+-- +goose StatementEnd
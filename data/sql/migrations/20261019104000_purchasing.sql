@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+-- par, reorder_point and pack_size are in unit_id. An ingredient is ordered
+-- from one supplier once its stock falls to the reorder point, enough packs
+-- to fill it up to par.
+CREATE TABLE par_levels (
+    id INTEGER PRIMARY KEY,
+    ingredient_id INTEGER NOT NULL UNIQUE,
+    supplier TEXT NOT NULL DEFAULT '',
+    par REAL NOT NULL,
+    reorder_point REAL NOT NULL,
+    pack_size REAL NOT NULL DEFAULT 0,
+    unit_id INTEGER NOT NULL,
+    FOREIGN KEY(ingredient_id) REFERENCES ingredients(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+    FOREIGN KEY(unit_id) REFERENCES units(id),
+    CHECK (reorder_point <= par)
+);
+
+CREATE TABLE purchase_orders (
+    id INTEGER PRIMARY KEY,
+    supplier TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL,
+    received_at INTEGER
+);
+
+-- quantity is in unit_id, packs is the number of packs it was rounded up to
+CREATE TABLE purchase_order_lines (
+    id INTEGER PRIMARY KEY,
+    purchase_order_id INTEGER NOT NULL,
+    ingredient_id INTEGER NOT NULL,
+    quantity REAL NOT NULL,
+    unit_id INTEGER NOT NULL,
+    packs REAL NOT NULL DEFAULT 0,
+    FOREIGN KEY(purchase_order_id) REFERENCES purchase_orders(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+    FOREIGN KEY(ingredient_id) REFERENCES ingredients(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+    FOREIGN KEY(unit_id) REFERENCES units(id),
+    UNIQUE(purchase_order_id, ingredient_id)
+);
+
+ALTER TABLE goods_receipts ADD COLUMN purchase_order_id INTEGER REFERENCES purchase_orders(id);
+-- +goose StatementEnd
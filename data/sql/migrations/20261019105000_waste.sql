@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- either an ingredient in unit_id or a product in pieces is wasted, cost is
+-- its value when it was logged
+CREATE TABLE waste (
+    id INTEGER PRIMARY KEY,
+    ingredient_id INTEGER,
+    product_id INTEGER,
+    quantity REAL NOT NULL,
+    unit_id INTEGER,
+    reason TEXT NOT NULL,
+    wasted_on INTEGER NOT NULL,
+    staff TEXT NOT NULL DEFAULT '',
+    cost REAL NOT NULL,
+    FOREIGN KEY(ingredient_id) REFERENCES ingredients(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+    FOREIGN KEY(product_id) REFERENCES products(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+    FOREIGN KEY(unit_id) REFERENCES units(id),
+    CHECK ((ingredient_id IS NULL) != (product_id IS NULL)),
+    CHECK (reason IN ('breakage', 'expired', 'spillage', 'overproduction', 'other'))
+);
+
+-- what a waste entry took out of stock in base units, to put it back when
+-- the entry is deleted
+CREATE TABLE waste_stock (
+    waste_id INTEGER NOT NULL,
+    ingredient_id INTEGER NOT NULL,
+    quantity REAL NOT NULL,
+    FOREIGN KEY(waste_id) REFERENCES waste(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+    FOREIGN KEY(ingredient_id) REFERENCES ingredients(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+    PRIMARY KEY(waste_id, ingredient_id)
+);
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- products given away for free, cost is their cost when they were recorded
+CREATE TABLE comps (
+    id INTEGER PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    quantity REAL NOT NULL,
+    reason TEXT NOT NULL,
+    comped_on INTEGER NOT NULL,
+    staff TEXT NOT NULL DEFAULT '',
+    cost REAL NOT NULL,
+    FOREIGN KEY(product_id) REFERENCES products(id)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+    CHECK (reason IN ('staff_meal', 'regular', 'complaint', 'promotion', 'other'))
+);
+-- +goose StatementEnd
@@ -6,7 +6,8 @@ select
     ip.unit_id,
     ip.quantity,
     ip.time_stamp,
-    ip.base_product_id
+    ip.base_product_id,
+    ip.deposit
 from ingredients i
 left join
     ingredient_prices ip
@@ -21,14 +22,14 @@ where (:ingredient_id is null or i.id =:ingredient_id)
 ;
 
 -- name: InsertIngredient :one
-insert into ingredients(name)
-values (?)
+insert into ingredients(name, abv, duty_excluded)
+values (?, ?, ?)
 returning *
 ;
 
 -- name: UpdateIngredient :one
 update ingredients
-set name=?
+set name=?, abv=?, duty_excluded=?
 where ( id = ? )
 returning *
 ;
@@ -45,8 +46,8 @@ where (id = ?)
 ;
 
 -- name: PutIngredientPrice :one
-insert into ingredient_prices (ingredient_id, price, quantity, unit_id, base_product_id)
-values (?, ?, ?, ?, ?)
+insert into ingredient_prices (ingredient_id, price, quantity, unit_id, base_product_id, deposit)
+values (?, ?, ?, ?, ?, ?)
 returning *
 ;
 
@@ -116,9 +117,10 @@ left join
 ;
 
 -- name: GetIngredientUsageForProductWithPrice :many
-select iu.*, i.*, ip.*
+select iu.*, i.*, ip.*, cast(coalesce(u.base_unit_id, u.id) as integer) as base_unit_id
 from ingredient_usage iu
 left join ingredients i on i.id = iu.ingredient_id
+left join units u on u.id = iu.unit_id
 left join
     ingredient_prices ip
     on ip.id = (
@@ -164,14 +166,28 @@ returning product_id
 ;
 
 -- name: GetProductsFromIngredient :many
-select distinct p.id, p.name
+select p.id, p.name
 from ingredient_usage iu
 join products p on p.id = iu.product_id
-where iu.ingredient_id = ?
+where iu.ingredient_id = sqlc.arg(ingredient_id)
+union
+select p.id, p.name
+from product_channel_packaging pcp
+join products p on p.id = pcp.product_id
+where pcp.ingredient_id = sqlc.arg(ingredient_id)
 ;
 
 -- name: GetProductsWithCost :many
-select p.id, p.name, p.price, p.multiplicator, p.category_id, pc.cost
+select
+    p.id,
+    p.name,
+    p.price,
+    p.multiplicator,
+    p.category_id,
+    p.prep_minutes,
+    p.deposit,
+    p.servings,
+    pc.cost
 from products p
 left join product_cost_cache pc on pc.product_id = p.id
 ;
@@ -187,6 +203,12 @@ select p.id, p.name
 from products p
 ;
 
+-- name: GetProduct :one
+select *
+from products
+where id = ?
+;
+
 -- name: GetProductWithCost :one
 select
     p.id,
@@ -194,6 +216,9 @@ select
     p.price,
     p.multiplicator,
     p.category_id,
+    p.prep_minutes,
+    p.deposit,
+    p.servings,
     cast(ifnull(sum(ip.price * iu.quantity), 0) as real) as cost
 from products p
 left join ingredient_usage iu on iu.product_id = p.id
@@ -215,7 +240,7 @@ returning *
 
 -- name: UpdateProduct :one
 update products
-set name=?, category_id=?, price=?, multiplicator=?
+set name=?, category_id=?, price=?, multiplicator=?, prep_minutes=?, deposit=?, servings=?
 where id=?
 returning *
 ;
@@ -225,21 +250,31 @@ delete from products
 where id = ?
 ;
 
+-- name: DeleteProductIngredientUsage :exec
+delete from ingredient_usage
+where product_id = ?
+;
+
+-- name: DeleteProductCost :exec
+delete from product_cost_cache
+where product_id = ?
+;
+
 -- name: GetCategories :many
 select *
 from categories
 ;
 
 -- name: PutCategory :one
-insert into categories (name, vat)
-values (?,?)
+insert into categories (name, vat, dine_in_vat_rate_id, takeaway_vat_rate_id)
+values (?, ?, ?, ?)
 returning *
 ;
 
 
 -- name: UpdateCategory :one
 update categories
-set name=?, vat=?
+set name=?, vat=?, dine_in_vat_rate_id=?, takeaway_vat_rate_id=?
 where id=?
 returning *
 ;
@@ -250,3 +285,891 @@ from categories
 where id = ?
 ;
 
+
+-- name: GetBundleComponents :many
+select
+    bc.id,
+    bc.bundle_id,
+    bc.product_id,
+    bc.quantity,
+    p.name,
+    p.price,
+    p.category_id,
+    pc.cost
+from bundle_components bc
+join products p on p.id = bc.product_id
+left join product_cost_cache pc on pc.product_id = p.id
+where bc.bundle_id = ?
+;
+
+-- name: GetBundleComponentProductIds :many
+select product_id
+from bundle_components
+where bundle_id = ?
+;
+
+-- name: GetBundlesFromProduct :many
+select distinct p.id, p.name
+from bundle_components bc
+join products p on p.id = bc.bundle_id
+where bc.product_id = ?
+;
+
+-- name: PutBundleComponent :one
+insert into bundle_components (bundle_id, product_id, quantity)
+values (?, ?, ?)
+returning *
+;
+
+-- name: UpdateBundleComponent :one
+update bundle_components
+set quantity=?
+where id=?
+returning *
+;
+
+-- name: DeleteBundleComponent :one
+delete from bundle_components
+where id = ?
+returning bundle_id
+;
+
+-- name: DeleteProductBundleComponents :exec
+delete from bundle_components
+where bundle_id = sqlc.arg(product_id) or product_id = sqlc.arg(product_id)
+;
+
+-- name: GetVatRates :many
+select *
+from vat_rates
+order by name
+;
+
+-- name: GetVatRatePeriods :many
+select *
+from vat_rate_periods
+order by vat_rate_id, valid_from
+;
+
+-- name: PutVatRate :one
+insert into vat_rates (name)
+values (?)
+returning *
+;
+
+-- name: PutVatRatePeriod :one
+insert into vat_rate_periods (vat_rate_id, rate, valid_from)
+values (?, ?, ?)
+returning *
+;
+
+-- name: DeleteVatRatePeriod :execrows
+delete from vat_rate_periods
+where id = ?
+;
+
+-- name: GetSalesChannels :many
+select *
+from sales_channels
+order by id
+;
+
+-- name: GetSalesChannel :one
+select *
+from sales_channels
+where id = ?
+;
+
+-- name: PutSalesChannel :one
+insert into sales_channels (name, vat_channel, commission_percent, fixed_fee, payment_fee_percent)
+values (?, ?, ?, ?, ?)
+returning *
+;
+
+-- name: UpdateSalesChannel :one
+update sales_channels
+set name=?, vat_channel=?, commission_percent=?, fixed_fee=?, payment_fee_percent=?
+where id=?
+returning *
+;
+
+-- name: DeleteSalesChannel :execrows
+delete from sales_channels
+where id = ?
+;
+
+-- name: GetProductChannelPrices :many
+select *
+from product_channel_prices
+;
+
+-- name: PutProductChannelPrice :exec
+insert into product_channel_prices (product_id, channel_id, price)
+values (?, ?, ?)
+on conflict (product_id, channel_id) do update
+set price = excluded.price
+;
+
+-- name: DeleteProductChannelPrice :exec
+delete from product_channel_prices
+where product_id = ? and channel_id = ?
+;
+
+-- name: DeleteChannelPrices :exec
+delete from product_channel_prices
+where channel_id = ?
+;
+
+-- name: DeleteProductChannelPrices :exec
+delete from product_channel_prices
+where product_id = ?
+;
+
+-- name: GetSettings :many
+select *
+from settings
+;
+
+-- name: GetSetting :one
+select value
+from settings
+where key = ?
+;
+
+-- name: PutSetting :exec
+insert into settings (key, value)
+values (?, ?)
+on conflict (key) do update
+set value = excluded.value
+;
+
+-- name: GetOverheadRules :many
+select *
+from overhead_rules
+order by id
+;
+
+-- name: PutOverheadRule :one
+insert into overhead_rules (name, kind, amount, category_id)
+values (?, ?, ?, ?)
+returning *
+;
+
+-- name: DeleteOverheadRule :execrows
+delete from overhead_rules
+where id = ?
+;
+
+-- name: GetChannelPackaging :many
+select
+    pcp.*,
+    i.name,
+    ip.price,
+    pc.cost as base_product_cost
+from product_channel_packaging pcp
+join ingredients i on i.id = pcp.ingredient_id
+left join
+    ingredient_prices ip
+    on ip.id = (
+        select id
+        from ingredient_prices as ip2
+        where ip2.ingredient_id = i.id
+        order by time_stamp desc
+        limit 1
+    )
+left join product_cost_cache pc on pc.product_id = ip.base_product_id
+order by pcp.id
+;
+
+-- name: PutChannelPackaging :one
+insert into product_channel_packaging (product_id, channel_id, ingredient_id, quantity, unit_id)
+values (?, ?, ?, ?, ?)
+returning *
+;
+
+-- name: DeleteChannelPackaging :execrows
+delete from product_channel_packaging
+where id = ?
+;
+
+-- name: DeleteChannelPackagingForChannel :exec
+delete from product_channel_packaging
+where channel_id = ?
+;
+
+-- name: DeleteProductChannelPackaging :exec
+delete from product_channel_packaging
+where product_id = ?
+;
+
+-- name: DeleteIngredientChannelPackaging :exec
+delete from product_channel_packaging
+where ingredient_id = ?
+;
+
+-- name: GetAllergens :many
+select *
+from allergens
+order by id
+;
+
+-- name: GetIngredientAllergens :many
+select *
+from ingredient_allergens
+order by ingredient_id, allergen_id
+;
+
+-- name: InsertIngredientAllergen :exec
+insert into ingredient_allergens (ingredient_id, allergen_id)
+values (?, ?)
+;
+
+-- name: DeleteIngredientAllergens :exec
+delete from ingredient_allergens
+where ingredient_id = ?
+;
+
+-- name: GetIngredientUsageGraph :many
+select iu.product_id, iu.ingredient_id, ip.base_product_id
+from ingredient_usage iu
+left join
+    ingredient_prices ip
+    on ip.id = (
+        select id
+        from ingredient_prices as ip2
+        where ip2.ingredient_id = iu.ingredient_id
+        order by time_stamp desc
+        limit 1
+    )
+;
+
+-- name: GetBundleComponentGraph :many
+select bundle_id, product_id
+from bundle_components
+;
+
+-- name: GetIngredientNutrition :many
+select *
+from ingredient_nutrition
+;
+
+-- name: PutIngredientNutrition :one
+insert into ingredient_nutrition (ingredient_id, kcal, fat, carbs, sugar, protein, salt)
+values (?, ?, ?, ?, ?, ?, ?)
+on conflict (ingredient_id) do update
+set
+    kcal = excluded.kcal,
+    fat = excluded.fat,
+    carbs = excluded.carbs,
+    sugar = excluded.sugar,
+    protein = excluded.protein,
+    salt = excluded.salt
+returning *
+;
+
+-- name: DeleteIngredientNutrition :exec
+delete from ingredient_nutrition
+where ingredient_id = ?
+;
+
+-- name: GetProductRecipe :one
+select *
+from product_recipes
+where product_id = ?
+;
+
+-- name: PutProductRecipe :one
+insert into product_recipes (product_id, notes, glassware, garnish)
+values (?, ?, ?, ?)
+on conflict (product_id) do update
+set notes = excluded.notes, glassware = excluded.glassware, garnish = excluded.garnish
+returning *
+;
+
+-- name: GetRecipeSteps :many
+select *
+from recipe_steps
+where product_id = ?
+order by position, id
+;
+
+-- name: GetRecipeStep :one
+select *
+from recipe_steps
+where id = ?
+;
+
+-- name: InsertRecipeStep :one
+insert into recipe_steps (product_id, position, text)
+values (
+    sqlc.arg(product_id),
+    (select ifnull(max(position), 0) + 1 from recipe_steps where product_id = sqlc.arg(product_id)),
+    sqlc.arg(text)
+)
+returning *
+;
+
+-- name: UpdateRecipeStep :one
+update recipe_steps
+set text = ?
+where id = ?
+returning *
+;
+
+-- name: UpdateRecipeStepPosition :exec
+update recipe_steps
+set position = ?
+where id = ?
+;
+
+-- name: DeleteRecipeStep :execrows
+delete from recipe_steps
+where id = ?
+;
+
+-- name: DeleteProductRecipe :exec
+delete from product_recipes
+where product_id = ?
+;
+
+-- name: DeleteProductRecipeSteps :exec
+delete from recipe_steps
+where product_id = ?
+;
+
+-- name: GetProductPhotos :many
+select *
+from product_photos
+where product_id = ?
+order by time_stamp, id
+;
+
+-- name: GetProductPhoto :one
+select *
+from product_photos
+where id = ?
+;
+
+-- name: InsertProductPhoto :one
+insert into product_photos (product_id, file_name, content_type, time_stamp)
+values (?, ?, ?, ?)
+returning *
+;
+
+-- name: DeleteProductPhoto :execrows
+delete from product_photos
+where id = ?
+;
+
+-- name: DeleteProductPhotos :exec
+delete from product_photos
+where product_id = ?
+;
+
+-- name: GetScenarios :many
+select *
+from scenarios
+order by time_stamp desc, id desc
+;
+
+-- name: GetScenario :one
+select *
+from scenarios
+where id = ?
+;
+
+-- name: InsertScenario :one
+insert into scenarios (name)
+values (?)
+returning *
+;
+
+-- name: CommitScenario :execrows
+update scenarios
+set committed_at = unixepoch('now')
+where id = ? and committed_at is null
+;
+
+-- name: DeleteScenario :execrows
+delete from scenarios
+where id = ?
+;
+
+-- name: GetScenarioOverrides :many
+select so.*, i.name
+from scenario_overrides so
+join ingredients i on i.id = so.ingredient_id
+where so.scenario_id = ?
+order by i.name
+;
+
+-- name: GetScenarioOverride :one
+select *
+from scenario_overrides
+where id = ?
+;
+
+-- name: PutScenarioOverride :one
+insert into scenario_overrides (scenario_id, ingredient_id, kind, amount)
+values (?, ?, ?, ?)
+on conflict (scenario_id, ingredient_id) do update
+set kind = excluded.kind, amount = excluded.amount
+returning *
+;
+
+-- name: DeleteScenarioOverride :execrows
+delete from scenario_overrides
+where id = ?
+;
+
+-- name: DeleteScenarioOverrides :exec
+delete from scenario_overrides
+where scenario_id = ?
+;
+
+-- name: DeleteIngredientScenarioOverrides :exec
+delete from scenario_overrides
+where ingredient_id = ?
+;
+
+-- name: SubstituteIngredientUsage :execrows
+update ingredient_usage
+set ingredient_id = sqlc.arg(to_ingredient_id), quantity = quantity * sqlc.arg(ratio)
+where ingredient_id = sqlc.arg(from_ingredient_id)
+;
+
+-- name: GetGoodsReceipts :many
+select gr.*, i.name, u.name as unit_name
+from goods_receipts gr
+join ingredients i on i.id = gr.ingredient_id
+join units u on u.id = gr.unit_id
+order by gr.received_at desc, gr.id desc
+;
+
+-- name: GetGoodsReceipt :one
+select *
+from goods_receipts
+where id = ?
+;
+
+-- name: GetLatestGoodsReceiptAt :one
+select cast(coalesce(max(received_at), 0) as integer) as received_at
+from goods_receipts
+where ingredient_id = ?
+;
+
+-- name: InsertGoodsReceipt :one
+insert into goods_receipts (
+    ingredient_id, quantity, unit_id, price, deposit, supplier, received_at, purchase_order_id
+)
+values (?, ?, ?, ?, ?, ?, ?, ?)
+returning *
+;
+
+-- name: DeleteGoodsReceipt :execrows
+delete from goods_receipts
+where id = ?
+;
+
+-- name: DeleteIngredientGoodsReceipts :exec
+delete from goods_receipts
+where ingredient_id = ?
+;
+
+-- name: GetStockLevels :many
+select sl.*, i.name
+from stock_levels sl
+join ingredients i on i.id = sl.ingredient_id
+order by i.name
+;
+
+-- name: AddStock :exec
+insert into stock_levels (ingredient_id, quantity)
+values (?, ?)
+on conflict (ingredient_id) do update
+set quantity = quantity + excluded.quantity
+;
+
+-- name: DeleteIngredientStock :exec
+delete from stock_levels
+where ingredient_id = ?
+;
+
+-- name: SetStock :exec
+insert into stock_levels (ingredient_id, quantity)
+values (?, ?)
+on conflict (ingredient_id) do update
+set quantity = excluded.quantity
+;
+
+-- name: GetStocktakes :many
+select *
+from stocktakes
+order by counted_at desc, id desc
+;
+
+-- name: GetStocktake :one
+select *
+from stocktakes
+where id = ?
+;
+
+-- name: InsertStocktake :one
+insert into stocktakes (counted_at)
+values (?)
+returning *
+;
+
+-- name: FinishStocktake :execrows
+update stocktakes
+set finished_at = unixepoch('now')
+where id = ? and finished_at is null
+;
+
+-- name: DeleteStocktake :execrows
+delete from stocktakes
+where id = ? and finished_at is null
+;
+
+-- name: GetStocktakeCounts :many
+select sc.*, i.name, u.name as unit_name, u.factor
+from stocktake_counts sc
+join ingredients i on i.id = sc.ingredient_id
+join units u on u.id = sc.unit_id
+where sc.stocktake_id = ?
+order by i.name
+;
+
+-- name: GetStocktakeCount :one
+select *
+from stocktake_counts
+where id = ?
+;
+
+-- name: PutStocktakeCount :one
+insert into stocktake_counts (stocktake_id, ingredient_id, quantity, unit_id)
+values (?, ?, ?, ?)
+on conflict (stocktake_id, ingredient_id) do update
+set quantity = excluded.quantity, unit_id = excluded.unit_id
+returning *
+;
+
+-- name: SetStocktakeCountExpected :exec
+update stocktake_counts
+set expected = ?
+where id = ?
+;
+
+-- name: DeleteStocktakeCount :execrows
+delete from stocktake_counts
+where id = ?
+;
+
+-- name: DeleteStocktakeCounts :exec
+delete from stocktake_counts
+where stocktake_id = ?
+;
+
+-- name: DeleteIngredientStocktakeCounts :exec
+delete from stocktake_counts
+where ingredient_id = ?
+;
+
+-- name: GetPreviousStocktakeCounts :many
+select sc.*, s.counted_at, u.factor
+from stocktake_counts sc
+join stocktakes s on s.id = sc.stocktake_id
+join units u on u.id = sc.unit_id
+where s.finished_at is not null
+    and (s.counted_at < sqlc.arg(counted_at)
+        or s.counted_at = sqlc.arg(counted_at) and s.id < sqlc.arg(stocktake_id))
+order by s.counted_at desc, s.id desc
+;
+
+-- name: GetLastCountedAt :many
+select sc.ingredient_id, cast(max(s.counted_at) as integer) as counted_at
+from stocktake_counts sc
+join stocktakes s on s.id = sc.stocktake_id
+where s.finished_at is not null
+group by sc.ingredient_id
+;
+
+-- name: GetPosItems :many
+select *
+from pos_items
+order by product_id is not null, item
+;
+
+-- name: InsertPosItem :one
+insert into pos_items (item, name)
+values (?, ?)
+returning *
+;
+
+-- name: SetPosItemProduct :execrows
+update pos_items
+set product_id = ?
+where id = ?
+;
+
+-- name: DeletePosItem :execrows
+delete from pos_items
+where id = ?
+;
+
+-- name: UnmapProductPosItems :exec
+update pos_items
+set product_id = null
+where product_id = ?
+;
+
+-- name: DeleteSalesInPeriod :execrows
+delete from sales
+where source = sqlc.arg(source) and sold_on >= sqlc.arg(from_day) and sold_on <= sqlc.arg(to_day)
+;
+
+-- name: InsertSale :one
+insert into sales (product_id, sold_on, shift, source, quantity, revenue)
+values (?, ?, ?, ?, ?, ?)
+returning *
+;
+
+-- name: GetSalesOfDay :many
+select *
+from sales
+where sold_on = ? and shift = ? and source = ?
+;
+
+-- name: DeleteSalesOfDay :exec
+delete from sales
+where sold_on = ? and shift = ? and source = ?
+;
+
+-- name: DeleteProductSales :exec
+delete from sales
+where product_id = ?
+;
+
+-- name: GetSaleDays :many
+select sold_on, shift, cast(sum(quantity) as real) as quantity, count(*) as products
+from sales
+where source = ?
+group by sold_on, shift
+order by sold_on desc, shift
+limit 30
+;
+
+-- name: GetSalesInPeriod :many
+select s.product_id, p.name, cast(sum(s.quantity) as real) as quantity
+from sales s
+join products p on p.id = s.product_id
+where s.sold_on >= sqlc.arg(from_day) and s.sold_on <= sqlc.arg(to_day)
+group by s.product_id, p.name
+order by p.name
+;
+
+-- name: GetParLevels :many
+select pl.*, i.name, u.name as unit_name, u.factor
+from par_levels pl
+join ingredients i on i.id = pl.ingredient_id
+join units u on u.id = pl.unit_id
+order by pl.supplier, i.name
+;
+
+-- name: PutParLevel :one
+insert into par_levels (ingredient_id, supplier, par, reorder_point, pack_size, unit_id)
+values (?, ?, ?, ?, ?, ?)
+on conflict (ingredient_id) do update
+set
+    supplier = excluded.supplier,
+    par = excluded.par,
+    reorder_point = excluded.reorder_point,
+    pack_size = excluded.pack_size,
+    unit_id = excluded.unit_id
+returning *
+;
+
+-- name: DeleteParLevel :execrows
+delete from par_levels
+where id = ?
+;
+
+-- name: DeleteIngredientParLevel :exec
+delete from par_levels
+where ingredient_id = ?
+;
+
+-- name: GetPurchaseOrders :many
+select po.*, count(pol.id) as lines
+from purchase_orders po
+left join purchase_order_lines pol on pol.purchase_order_id = po.id
+group by po.id
+order by po.created_at desc, po.id desc
+;
+
+-- name: GetPurchaseOrder :one
+select *
+from purchase_orders
+where id = ?
+;
+
+-- name: InsertPurchaseOrder :one
+insert into purchase_orders (supplier, created_at)
+values (?, ?)
+returning *
+;
+
+-- name: DeletePurchaseOrder :execrows
+delete from purchase_orders
+where id = ? and received_at is null
+;
+
+-- name: ReceivePurchaseOrder :execrows
+update purchase_orders
+set received_at = ?
+where id = ? and received_at is null
+;
+
+-- name: GetPurchaseOrderLines :many
+select pol.*, i.name, u.name as unit_name, u.factor
+from purchase_order_lines pol
+join ingredients i on i.id = pol.ingredient_id
+join units u on u.id = pol.unit_id
+where pol.purchase_order_id = ?
+order by i.name
+;
+
+-- name: InsertPurchaseOrderLine :one
+insert into purchase_order_lines (purchase_order_id, ingredient_id, quantity, unit_id, packs)
+values (?, ?, ?, ?, ?)
+returning *
+;
+
+-- name: DeletePurchaseOrderLines :exec
+delete from purchase_order_lines
+where purchase_order_id = ?
+;
+
+-- name: DeleteIngredientPurchaseOrderLines :exec
+delete from purchase_order_lines
+where ingredient_id = ?
+;
+
+-- name: GetOpenPurchaseOrderQuantities :many
+select pol.ingredient_id, cast(sum(pol.quantity / u.factor) as real) as quantity
+from purchase_order_lines pol
+join purchase_orders po on po.id = pol.purchase_order_id
+join units u on u.id = pol.unit_id
+where po.received_at is null
+group by pol.ingredient_id
+;
+
+-- name: GetWaste :many
+select
+    w.*,
+    cast(coalesce(i.name, p.name, '') as text) as name,
+    cast(coalesce(u.name, '') as text) as unit_name
+from waste w
+left join ingredients i on i.id = w.ingredient_id
+left join products p on p.id = w.product_id
+left join units u on u.id = w.unit_id
+where w.wasted_on >= sqlc.arg(from_day) and w.wasted_on <= sqlc.arg(to_day)
+order by w.wasted_on desc, w.id desc
+;
+
+-- name: InsertWaste :one
+insert into waste (ingredient_id, product_id, quantity, unit_id, reason, wasted_on, staff, cost)
+values (?, ?, ?, ?, ?, ?, ?, ?)
+returning *
+;
+
+-- name: DeleteWaste :execrows
+delete from waste
+where id = ?
+;
+
+-- name: DeleteIngredientWaste :exec
+delete from waste
+where ingredient_id = ?
+;
+
+-- name: DeleteProductWasteStock :exec
+delete from waste_stock
+where waste_id in (select id from waste where product_id = ?)
+;
+
+-- name: DeleteProductWaste :exec
+delete from waste
+where product_id = ?
+;
+
+-- name: GetWasteStock :many
+select *
+from waste_stock
+where waste_id = ?
+;
+
+-- name: InsertWasteStock :exec
+insert into waste_stock (waste_id, ingredient_id, quantity)
+values (?, ?, ?)
+;
+
+-- name: DeleteWasteStock :exec
+delete from waste_stock
+where waste_id = ?
+;
+
+-- name: DeleteIngredientWasteStock :exec
+delete from waste_stock
+where ingredient_id = ?
+;
+
+-- name: SubtractStock :execrows
+update stock_levels
+set quantity = quantity - ?
+where ingredient_id = ?
+;
+
+-- name: GetComps :many
+select c.*, p.name
+from comps c
+join products p on p.id = c.product_id
+where c.comped_on >= sqlc.arg(from_day) and c.comped_on <= sqlc.arg(to_day)
+order by c.comped_on desc, c.id desc
+;
+
+-- name: InsertComp :one
+insert into comps (product_id, quantity, reason, comped_on, staff, cost)
+values (?, ?, ?, ?, ?, ?)
+returning *
+;
+
+-- name: DeleteComp :execrows
+delete from comps
+where id = ?
+;
+
+-- name: DeleteProductComps :exec
+delete from comps
+where product_id = ?
+;
+
+-- name: GetCompsInPeriod :many
+select c.product_id, p.name, cast(sum(c.quantity) as real) as quantity
+from comps c
+join products p on p.id = c.product_id
+where c.comped_on >= sqlc.arg(from_day) and c.comped_on <= sqlc.arg(to_day)
+group by c.product_id, p.name
+order by p.name
+;
+
+-- name: GetCompCostsByMonth :many
+select
+    cast(strftime('%Y-%m', comped_on, 'unixepoch') as text) as month,
+    reason,
+    cast(sum(cost) as real) as cost
+from comps
+where comped_on >= sqlc.arg(from_day) and comped_on <= sqlc.arg(to_day)
+group by month, reason
+order by month desc, reason
+;
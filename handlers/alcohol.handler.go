@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (ph *PriceCalcHandler) getProductAlcohol(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("product-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse product id "+err.Error())
+	}
+
+	_, err = ph.service.GetProductWithCost(productId)
+	if err != nil {
+		return c.String(http.StatusNotFound, "could not get product "+err.Error())
+	}
+
+	alcohol, err := ph.service.GetProductAlcohol(c.Request().Context(), productId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get alcohol "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, alcohol)
+}
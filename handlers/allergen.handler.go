@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// parseAllergenIds reads the ids of all checked allergen checkboxes
+func parseAllergenIds(c echo.Context) ([]int64, error) {
+	form, err := c.FormParams()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(form["allergen"]))
+	for _, value := range form["allergen"] {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mike-jl/price_calc/components"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+func (ph *PriceCalcHandler) getBundleViewModel(
+	c echo.Context,
+	bundleId int64,
+) (*viewmodels.BundleEditViewModel, error) {
+	bundle, err := ph.service.GetBundle(c.Request().Context(), bundleId)
+	if err != nil {
+		return nil, err
+	}
+	productNames, err := ph.service.GetProductNames(c.Request().Context())
+	if err != nil {
+		return nil, err
+	}
+	return &viewmodels.BundleEditViewModel{
+		Bundle:       *bundle,
+		ProductNames: productNames,
+	}, nil
+}
+
+func (ph *PriceCalcHandler) renderBundle(c echo.Context, statusCode int, bundleId int64) error {
+	viewModel, err := ph.getBundleViewModel(c, bundleId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get bundle "+err.Error())
+	}
+	return render(c, statusCode, components.BundleDetails(*viewModel))
+}
+
+func (ph *PriceCalcHandler) getBundleEditPage(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("product-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse product id "+err.Error())
+	}
+	viewModel, err := ph.getBundleViewModel(c, productId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get bundle "+err.Error())
+	}
+	return render(c, http.StatusOK, components.Index(components.BundleDetails(*viewModel)))
+}
+
+func (ph *PriceCalcHandler) putBundleComponent(c echo.Context) error {
+	bundleId, err := strconv.ParseInt(c.Param("product-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse product id "+err.Error())
+	}
+	productId, err := strconv.ParseInt(c.FormValue("product"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse component product id "+err.Error())
+	}
+	quantity, err := strconv.ParseFloat(c.FormValue("quantity"), 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse quantity "+err.Error())
+	}
+
+	circ, err := ph.service.CheckBundleCircularDependency(
+		c.Request().Context(),
+		bundleId,
+		productId,
+	)
+	if err != nil {
+		return c.String(
+			http.StatusInternalServerError,
+			"could not check circular dependency "+err.Error(),
+		)
+	}
+	if circ {
+		return c.String(
+			http.StatusConflict,
+			"Can't add component because the bundle would contain itself!",
+		)
+	}
+
+	_, err = ph.service.PutBundleComponent(c.Request().Context(), bundleId, productId, quantity)
+	if err != nil {
+		return c.String(
+			http.StatusInternalServerError,
+			"could not insert bundle component "+err.Error(),
+		)
+	}
+
+	return ph.renderBundle(c, http.StatusOK, bundleId)
+}
+
+func (ph *PriceCalcHandler) postBundleComponent(c echo.Context) error {
+	bundleComponentId, err := strconv.ParseInt(c.Param("bundle-component-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse bundle component id "+err.Error())
+	}
+	quantity, err := strconv.ParseFloat(c.FormValue("quantity"), 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse quantity "+err.Error())
+	}
+
+	component, err := ph.service.UpdateBundleComponent(
+		c.Request().Context(),
+		bundleComponentId,
+		quantity,
+	)
+	if err != nil {
+		return c.String(
+			http.StatusInternalServerError,
+			"could not update bundle component "+err.Error(),
+		)
+	}
+
+	return ph.renderBundle(c, http.StatusOK, component.BundleID)
+}
+
+func (ph *PriceCalcHandler) deleteBundleComponent(c echo.Context) error {
+	bundleComponentId, err := strconv.ParseInt(c.Param("bundle-component-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse bundle component id "+err.Error())
+	}
+
+	bundleId, err := ph.service.DeleteBundleComponent(c.Request().Context(), bundleComponentId)
+	if err != nil {
+		return c.String(
+			http.StatusInternalServerError,
+			"could not delete bundle component "+err.Error(),
+		)
+	}
+
+	return ph.renderBundle(c, http.StatusOK, bundleId)
+}
@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mike-jl/price_calc/components"
+	"github.com/mike-jl/price_calc/services"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+func parseSalesChannelForm(c echo.Context) (*services.UpdateSalesChannelParams, error) {
+	name := strings.TrimSpace(c.FormValue("name"))
+	if name == "" {
+		return nil, errors.New("channel name is empty")
+	}
+	commission, err := strconv.ParseFloat(c.FormValue("commission-percent"), 64)
+	if err != nil {
+		return nil, errors.New("could not parse commission " + err.Error())
+	}
+	fixedFee, err := strconv.ParseFloat(c.FormValue("fixed-fee"), 64)
+	if err != nil {
+		return nil, errors.New("could not parse fixed fee " + err.Error())
+	}
+	paymentFee, err := strconv.ParseFloat(c.FormValue("payment-fee-percent"), 64)
+	if err != nil {
+		return nil, errors.New("could not parse payment fee " + err.Error())
+	}
+	return &services.UpdateSalesChannelParams{
+		Name:              name,
+		VatChannel:        services.VatChannel(c.FormValue("vat-channel")),
+		CommissionPercent: commission,
+		FixedFee:          fixedFee,
+		PaymentFeePercent: paymentFee,
+	}, nil
+}
+
+func (ph *PriceCalcHandler) renderChannels(c echo.Context, statusCode int, page bool) error {
+	channels, err := ph.service.GetSalesChannels(c.Request().Context())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get channels "+err.Error())
+	}
+	if page {
+		return render(c, statusCode, components.Index(components.Channels(channels)))
+	}
+	return render(c, statusCode, components.Channels(channels))
+}
+
+func (ph *PriceCalcHandler) getChannels(c echo.Context) error {
+	return ph.renderChannels(c, http.StatusOK, true)
+}
+
+func (ph *PriceCalcHandler) putChannel(c echo.Context) error {
+	params, err := parseSalesChannelForm(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	_, err = ph.service.PutSalesChannel(c.Request().Context(), *params)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not insert channel "+err.Error())
+	}
+	return ph.renderChannels(c, http.StatusOK, false)
+}
+
+func (ph *PriceCalcHandler) postChannel(c echo.Context) error {
+	channelId, err := strconv.ParseInt(c.Param("channel-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse channel id "+err.Error())
+	}
+	params, err := parseSalesChannelForm(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	params.ID = channelId
+	_, err = ph.service.UpdateSalesChannel(c.Request().Context(), *params)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not update channel "+err.Error())
+	}
+	return ph.renderChannels(c, http.StatusOK, false)
+}
+
+func (ph *PriceCalcHandler) deleteChannel(c echo.Context) error {
+	channelId, err := strconv.ParseInt(c.Param("channel-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse channel id "+err.Error())
+	}
+	err = ph.service.DeleteSalesChannel(c.Request().Context(), channelId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not delete channel "+err.Error())
+	}
+	return ph.renderChannels(c, http.StatusOK, false)
+}
+
+func (ph *PriceCalcHandler) renderProductChannels(
+	c echo.Context,
+	statusCode int,
+	productId int64,
+	page bool,
+) error {
+	product, err := ph.service.GetProductChannelMargins(c.Request().Context(), productId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get channel margins "+err.Error())
+	}
+	ingredients, err := ph.service.GetIngredientsWithPrice(c.Request().Context())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get ingredients "+err.Error())
+	}
+	units, err := ph.service.GetUnitsMap(c.Request().Context())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get units "+err.Error())
+	}
+
+	viewModel := viewmodels.ProductChannelsViewModel{
+		Product:     *product,
+		Ingredients: make(map[int64]string, len(ingredients)),
+		Units:       units,
+	}
+	for _, ingredient := range ingredients {
+		viewModel.Ingredients[ingredient.Ingredient.ID] = ingredient.Ingredient.Name
+	}
+
+	if page {
+		return render(c, statusCode, components.Index(components.ProductChannels(viewModel)))
+	}
+	return render(c, statusCode, components.ProductChannels(viewModel))
+}
+
+func (ph *PriceCalcHandler) getProductChannels(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("product-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse product id "+err.Error())
+	}
+	return ph.renderProductChannels(c, http.StatusOK, productId, true)
+}
+
+func (ph *PriceCalcHandler) postProductChannelPrice(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("product-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse product id "+err.Error())
+	}
+	channelId, err := strconv.ParseInt(c.Param("channel-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse channel id "+err.Error())
+	}
+
+	var price *float64
+	if priceValue := strings.TrimSpace(c.FormValue("price")); priceValue != "" {
+		parsed, err := strconv.ParseFloat(priceValue, 64)
+		if err != nil {
+			return c.String(http.StatusBadRequest, "could not parse price "+err.Error())
+		}
+		price = &parsed
+	}
+
+	err = ph.service.SetProductChannelPrice(c.Request().Context(), productId, channelId, price)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not set channel price "+err.Error())
+	}
+	return ph.renderProductChannels(c, http.StatusOK, productId, false)
+}
+
+func (ph *PriceCalcHandler) putChannelPackaging(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("product-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse product id "+err.Error())
+	}
+	channelId, err := strconv.ParseInt(c.Param("channel-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse channel id "+err.Error())
+	}
+	ingredientId, err := strconv.ParseInt(c.FormValue("ingredient"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse ingredient id "+err.Error())
+	}
+	unitId, err := strconv.ParseInt(c.FormValue("unit"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse unit id "+err.Error())
+	}
+	amount, err := strconv.ParseFloat(c.FormValue("amount"), 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse amount "+err.Error())
+	}
+
+	_, err = ph.service.PutChannelPackaging(
+		c.Request().Context(),
+		productId,
+		channelId,
+		ingredientId,
+		unitId,
+		amount,
+	)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not insert packaging "+err.Error())
+	}
+	return ph.renderProductChannels(c, http.StatusOK, productId, false)
+}
+
+func (ph *PriceCalcHandler) deleteChannelPackaging(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("product-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse product id "+err.Error())
+	}
+	packagingId, err := strconv.ParseInt(c.Param("packaging-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse packaging id "+err.Error())
+	}
+	err = ph.service.DeleteChannelPackaging(c.Request().Context(), packagingId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not delete packaging "+err.Error())
+	}
+	return ph.renderProductChannels(c, http.StatusOK, productId, false)
+}
+
+func (ph *PriceCalcHandler) getChannelReport(c echo.Context) error {
+	report, err := ph.service.GetChannelReport(c.Request().Context())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get channel report "+err.Error())
+	}
+	return render(c, http.StatusOK, components.Index(components.ChannelReport(*report)))
+}
+
+func (ph *PriceCalcHandler) getChannelReportCsv(c echo.Context) error {
+	report, err := ph.service.GetChannelReport(c.Request().Context())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get channel report "+err.Error())
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	c.Response().Header().Set(
+		echo.HeaderContentDisposition,
+		`attachment; filename="channel_margins.csv"`,
+	)
+	c.Response().WriteHeader(http.StatusOK)
+
+	w := csv.NewWriter(c.Response())
+	err = w.Write([]string{
+		"product", "channel", "price", "vat", "net_price",
+		"fees", "cost", "packaging", "margin", "margin_percent", "deposit",
+	})
+	if err != nil {
+		return err
+	}
+	for _, product := range report.Products {
+		for _, margin := range product.Margins {
+			err = w.Write([]string{
+				product.Product.Product.Name,
+				margin.ChannelName,
+				formatCsvFloat(margin.Price),
+				formatCsvFloat(margin.Vat),
+				formatCsvFloat(margin.NetPrice),
+				formatCsvFloat(margin.Fees),
+				formatCsvFloat(margin.Cost),
+				formatCsvFloat(margin.Packaging),
+				formatCsvFloat(margin.Margin),
+				formatCsvFloat(margin.MarginPercent),
+				formatCsvFloat(product.Product.Product.Deposit),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func formatCsvFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
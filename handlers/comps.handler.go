@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mike-jl/price_calc/components"
+	"github.com/mike-jl/price_calc/services"
+)
+
+func parseCompForm(c echo.Context) (*services.CompParams, error) {
+	params := services.CompParams{
+		Reason: c.FormValue("reason"),
+		Staff:  c.FormValue("staff"),
+	}
+	var err error
+	params.ProductID, err = strconv.ParseInt(c.FormValue("product-id"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	params.Quantity, err = strconv.ParseFloat(c.FormValue("quantity"), 64)
+	if err != nil {
+		return nil, err
+	}
+	params.CompedOn, err = time.Parse(time.DateOnly, c.FormValue("comped-on"))
+	if err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+// parseCompMonth reads the month as YYYY-MM, the current month by default
+func parseCompMonth(c echo.Context) (time.Time, error) {
+	value := c.FormValue("month")
+	if value == "" {
+		return time.Now(), nil
+	}
+	return time.Parse("2006-01", value)
+}
+
+func (ph *PriceCalcHandler) renderComps(c echo.Context, statusCode int, page bool) error {
+	month, err := parseCompMonth(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse month "+err.Error())
+	}
+	comps, err := ph.service.GetComps(c.Request().Context(), month)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get comps "+err.Error())
+	}
+	if page {
+		return render(c, statusCode, components.Index(components.Comps(*comps)))
+	}
+	return render(c, statusCode, components.Comps(*comps))
+}
+
+func (ph *PriceCalcHandler) getComps(c echo.Context) error {
+	return ph.renderComps(c, http.StatusOK, true)
+}
+
+func (ph *PriceCalcHandler) putComp(c echo.Context) error {
+	params, err := parseCompForm(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse comp "+err.Error())
+	}
+
+	err = ph.service.LogComp(c.Request().Context(), *params)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not log comp "+err.Error())
+	}
+	return ph.renderComps(c, http.StatusOK, false)
+}
+
+func (ph *PriceCalcHandler) deleteComp(c echo.Context) error {
+	compId, err := strconv.ParseInt(c.Param("comp-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse comp id "+err.Error())
+	}
+
+	err = ph.service.DeleteComp(c.Request().Context(), compId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not delete comp "+err.Error())
+	}
+	return ph.renderComps(c, http.StatusOK, false)
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mike-jl/price_calc/components"
+)
+
+// parseSalesPeriod reads the from and to day of a sales report from the query
+// or the form, the default is the last seven days
+func parseSalesPeriod(c echo.Context) (time.Time, time.Time, error) {
+	to, err := parseSalesDay(c.FormValue("to"))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	from := to.AddDate(0, 0, -6)
+	if c.FormValue("from") != "" {
+		from, err = time.Parse(time.DateOnly, c.FormValue("from"))
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	return from, to, nil
+}
+
+func (ph *PriceCalcHandler) getConsumption(c echo.Context) error {
+	from, to, err := parseSalesPeriod(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse period "+err.Error())
+	}
+
+	consumption, err := ph.service.GetConsumption(c.Request().Context(), from, to)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get consumption "+err.Error())
+	}
+	return render(c, http.StatusOK, components.Index(components.Consumption(*consumption)))
+}
+
+func (ph *PriceCalcHandler) getConsumptionJson(c echo.Context) error {
+	from, to, err := parseSalesPeriod(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse period "+err.Error())
+	}
+
+	consumption, err := ph.service.GetConsumption(c.Request().Context(), from, to)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get consumption "+err.Error())
+	}
+	return c.JSON(http.StatusOK, consumption)
+}
+
+func (ph *PriceCalcHandler) getConsumptionCsv(c echo.Context) error {
+	from, to, err := parseSalesPeriod(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse period "+err.Error())
+	}
+
+	consumption, err := ph.service.GetConsumption(c.Request().Context(), from, to)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get consumption "+err.Error())
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	c.Response().Header().Set(
+		echo.HeaderContentDisposition,
+		`attachment; filename="consumption.csv"`,
+	)
+	c.Response().WriteHeader(http.StatusOK)
+
+	w := csv.NewWriter(c.Response())
+	err = w.Write([]string{"ingredient", "quantity", "unit", "pack_size", "packs", "cost"})
+	if err != nil {
+		return err
+	}
+	for _, line := range consumption.Lines {
+		// unknown costs are left empty
+		cost := ""
+		if line.Cost != nil {
+			cost = formatCsvFloat(*line.Cost)
+		}
+		err = w.Write([]string{
+			line.Name,
+			strconv.FormatFloat(line.Quantity, 'f', 3, 64),
+			line.Unit,
+			formatCsvFloat(line.PackSize),
+			strconv.FormatFloat(line.Packs, 'f', 3, 64),
+			cost,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mike-jl/price_calc/components"
+)
+
+func (ph *PriceCalcHandler) getProductCostTree(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("product-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse product id "+err.Error())
+	}
+
+	tree, err := ph.service.GetProductCostTree(c.Request().Context(), productId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get cost tree "+err.Error())
+	}
+	return render(c, http.StatusOK, components.CostTree(*tree))
+}
+
+func (ph *PriceCalcHandler) getProductCostTreeJson(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("product-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse product id "+err.Error())
+	}
+
+	tree, err := ph.service.GetProductCostTree(c.Request().Context(), productId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get cost tree "+err.Error())
+	}
+	return c.JSON(http.StatusOK, tree)
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mike-jl/price_calc/components"
+	"github.com/mike-jl/price_calc/services"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+func (ph *PriceCalcHandler) renderCosting(c echo.Context, statusCode int, page bool) error {
+	settings, err := ph.service.GetCostSettings(c.Request().Context())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get cost settings "+err.Error())
+	}
+	categories, err := ph.service.GetCategories()
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get categories "+err.Error())
+	}
+
+	viewModel := viewmodels.CostingViewModel{
+		Settings:   *settings,
+		Categories: make(map[int64]string, len(categories)),
+	}
+	for _, category := range categories {
+		viewModel.Categories[category.ID] = category.Name
+	}
+
+	if page {
+		return render(c, statusCode, components.Index(components.Costing(viewModel)))
+	}
+	return render(c, statusCode, components.Costing(viewModel))
+}
+
+func (ph *PriceCalcHandler) getCosting(c echo.Context) error {
+	return ph.renderCosting(c, http.StatusOK, true)
+}
+
+func (ph *PriceCalcHandler) postCostSettings(c echo.Context) error {
+	laborRate, err := strconv.ParseFloat(c.FormValue("labor-rate"), 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse labor rate "+err.Error())
+	}
+	dutyRate, err := strconv.ParseFloat(c.FormValue("spirits-duty-rate"), 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse spirits duty rate "+err.Error())
+	}
+	standardDrink, err := strconv.ParseFloat(c.FormValue("standard-drink-grams"), 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse standard drink "+err.Error())
+	}
+
+	err = ph.service.UpdateCostSettings(c.Request().Context(), services.UpdateCostSettingsParams{
+		LaborRate:          laborRate,
+		CostBasis:          services.CostBasis(c.FormValue("cost-basis")),
+		StockValuation:     services.StockValuation(c.FormValue("stock-valuation")),
+		SpiritsDutyRate:    dutyRate,
+		StandardDrinkGrams: standardDrink,
+	})
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not update cost settings "+err.Error())
+	}
+	return ph.renderCosting(c, http.StatusOK, false)
+}
+
+func (ph *PriceCalcHandler) putOverheadRule(c echo.Context) error {
+	name := strings.TrimSpace(c.FormValue("name"))
+	amount, err := strconv.ParseFloat(c.FormValue("amount"), 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse amount "+err.Error())
+	}
+	categoryId, err := parseOptionalId(c.FormValue("category-id"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse category id "+err.Error())
+	}
+
+	_, err = ph.service.PutOverheadRule(
+		c.Request().Context(),
+		name,
+		services.OverheadKind(c.FormValue("kind")),
+		amount,
+		categoryId,
+	)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not insert overhead rule "+err.Error())
+	}
+	return ph.renderCosting(c, http.StatusOK, false)
+}
+
+func (ph *PriceCalcHandler) deleteOverheadRule(c echo.Context) error {
+	ruleId, err := strconv.ParseInt(c.Param("overhead-rule-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse overhead rule id "+err.Error())
+	}
+	err = ph.service.DeleteOverheadRule(c.Request().Context(), ruleId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not delete overhead rule "+err.Error())
+	}
+	return ph.renderCosting(c, http.StatusOK, false)
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mike-jl/price_calc/components"
+)
+
+type menuEngineeringParams struct {
+	from       time.Time
+	to         time.Time
+	categoryId *int64
+}
+
+func parseMenuEngineeringParams(c echo.Context) (*menuEngineeringParams, error) {
+	from, to, err := parseSalesPeriod(c)
+	if err != nil {
+		return nil, err
+	}
+	categoryId, err := parseOptionalId(c.QueryParam("category-id"))
+	if err != nil {
+		return nil, err
+	}
+	return &menuEngineeringParams{from, to, categoryId}, nil
+}
+
+func (ph *PriceCalcHandler) getMenuEngineering(c echo.Context) error {
+	params, err := parseMenuEngineeringParams(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse report parameters "+err.Error())
+	}
+
+	report, err := ph.service.GetMenuEngineering(c.Request().Context(), params.from, params.to, params.categoryId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get menu engineering "+err.Error())
+	}
+	return render(c, http.StatusOK, components.Index(components.MenuEngineering(*report)))
+}
+
+func (ph *PriceCalcHandler) getMenuEngineeringJson(c echo.Context) error {
+	params, err := parseMenuEngineeringParams(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse report parameters "+err.Error())
+	}
+
+	report, err := ph.service.GetMenuEngineering(c.Request().Context(), params.from, params.to, params.categoryId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get menu engineering "+err.Error())
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+func (ph *PriceCalcHandler) getMenuEngineeringCsv(c echo.Context) error {
+	params, err := parseMenuEngineeringParams(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse report parameters "+err.Error())
+	}
+
+	report, err := ph.service.GetMenuEngineering(c.Request().Context(), params.from, params.to, params.categoryId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get menu engineering "+err.Error())
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	c.Response().Header().Set(
+		echo.HeaderContentDisposition,
+		`attachment; filename="menu_engineering.csv"`,
+	)
+	c.Response().WriteHeader(http.StatusOK)
+
+	w := csv.NewWriter(c.Response())
+	err = w.Write([]string{
+		"category", "product", "sold", "menu_mix", "price", "net_price",
+		"cost", "margin", "total_margin", "class",
+	})
+	if err != nil {
+		return err
+	}
+	for _, section := range report.Sections {
+		for _, item := range section.Items {
+			err = w.Write([]string{
+				section.Name,
+				item.Name,
+				strconv.FormatFloat(item.Sold, 'f', -1, 64),
+				formatCsvFloat(item.MenuMix),
+				formatCsvFloat(item.Price),
+				formatCsvFloat(item.NetPrice),
+				formatCsvFloat(item.Cost),
+				formatCsvFloat(item.Margin),
+				formatCsvFloat(item.TotalMargin),
+				item.Class,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mike-jl/price_calc/components"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+func (ph *PriceCalcHandler) getNutrition(c echo.Context) error {
+	rows, err := ph.service.GetIngredientNutritionRows(c.Request().Context())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get nutrition "+err.Error())
+	}
+	return render(c, http.StatusOK, components.Index(components.Nutrition(rows)))
+}
+
+func parseNutritionForm(c echo.Context) (*viewmodels.NutritionFacts, error) {
+	facts := viewmodels.NutritionFacts{}
+	fields := []struct {
+		name  string
+		value *float64
+	}{
+		{"kcal", &facts.Kcal},
+		{"fat", &facts.Fat},
+		{"carbs", &facts.Carbs},
+		{"sugar", &facts.Sugar},
+		{"protein", &facts.Protein},
+		{"salt", &facts.Salt},
+	}
+	for _, field := range fields {
+		value, err := parseOptionalFloat(c.FormValue(field.name))
+		if err != nil {
+			return nil, err
+		}
+		*field.value = value
+	}
+	return &facts, nil
+}
+
+func (ph *PriceCalcHandler) putIngredientNutrition(c echo.Context) error {
+	ingredientId, err := strconv.ParseInt(c.Param("ingredient-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse ingredient id "+err.Error())
+	}
+	facts, err := parseNutritionForm(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse nutrition "+err.Error())
+	}
+
+	err = ph.service.PutIngredientNutrition(c.Request().Context(), ingredientId, *facts)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not save nutrition "+err.Error())
+	}
+
+	row, err := ph.service.GetIngredientNutritionRow(c.Request().Context(), ingredientId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get nutrition "+err.Error())
+	}
+	return render(c, http.StatusOK, components.IngredientNutritionRow(*row))
+}
+
+func (ph *PriceCalcHandler) getProductNutrition(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("product-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse product id "+err.Error())
+	}
+
+	_, err = ph.service.GetProductWithCost(productId)
+	if err != nil {
+		return c.String(http.StatusNotFound, "could not get product "+err.Error())
+	}
+
+	nutrition, err := ph.service.GetProductNutrition(c.Request().Context(), productId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get nutrition "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, nutrition)
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mike-jl/price_calc/components"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+func (ph *PriceCalcHandler) renderPosImport(
+	c echo.Context,
+	statusCode int,
+	result *viewmodels.PosImportResult,
+	page bool,
+) error {
+	posImport, err := ph.service.GetPosImport(c.Request().Context())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get pos import "+err.Error())
+	}
+	posImport.Result = result
+	if page {
+		return render(c, statusCode, components.Index(components.PosImport(*posImport)))
+	}
+	return render(c, statusCode, components.PosImport(*posImport))
+}
+
+func (ph *PriceCalcHandler) getPosImport(c echo.Context) error {
+	return ph.renderPosImport(c, http.StatusOK, nil, true)
+}
+
+func (ph *PriceCalcHandler) postPosImport(c echo.Context) error {
+	fileHeader, err := c.FormFile("export")
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not read export "+err.Error())
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not read export "+err.Error())
+	}
+	defer file.Close()
+
+	result, err := ph.service.ImportPosSales(c.Request().Context(), file)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not import sales "+err.Error())
+	}
+	return ph.renderPosImport(c, http.StatusOK, result, false)
+}
+
+func (ph *PriceCalcHandler) postPosSettings(c echo.Context) error {
+	err := ph.service.UpdatePosSettings(c.Request().Context(), viewmodels.PosSettings{
+		Delimiter:      c.FormValue("delimiter"),
+		DateFormat:     c.FormValue("date-format"),
+		DateColumn:     c.FormValue("date-column"),
+		ItemColumn:     c.FormValue("item-column"),
+		NameColumn:     c.FormValue("name-column"),
+		QuantityColumn: c.FormValue("quantity-column"),
+		RevenueColumn:  c.FormValue("revenue-column"),
+	})
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not update pos settings "+err.Error())
+	}
+	return ph.renderPosImport(c, http.StatusOK, nil, false)
+}
+
+func (ph *PriceCalcHandler) putPosItem(c echo.Context) error {
+	itemId, err := strconv.ParseInt(c.Param("pos-item-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse pos item id "+err.Error())
+	}
+	productId, err := parseOptionalId(c.FormValue("product-id"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse product id "+err.Error())
+	}
+
+	err = ph.service.SetPosItemProduct(c.Request().Context(), itemId, productId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not map pos item "+err.Error())
+	}
+	return ph.renderPosImport(c, http.StatusOK, nil, false)
+}
+
+func (ph *PriceCalcHandler) deletePosItem(c echo.Context) error {
+	itemId, err := strconv.ParseInt(c.Param("pos-item-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse pos item id "+err.Error())
+	}
+
+	err = ph.service.DeletePosItem(c.Request().Context(), itemId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not delete pos item "+err.Error())
+	}
+	return ph.renderPosImport(c, http.StatusOK, nil, false)
+}
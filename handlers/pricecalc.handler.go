@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/a-h/templ"
 	"github.com/labstack/echo/v4"
@@ -36,6 +37,28 @@ func render(ctx echo.Context, statusCode int, t templ.Component) error {
 	return ctx.HTML(statusCode, buf.String())
 }
 
+// parseOptionalFloat parses an optional form value, an empty value is 0.
+func parseOptionalFloat(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// parseNullableFloat returns nil for an empty value
+func parseNullableFloat(value string) (*float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
 func (ph *PriceCalcHandler) getIngredients(c echo.Context) error {
 	ingredients, err := ph.service.GetIngredientsWithPrice(c.Request().Context())
 	if err != nil {
@@ -54,6 +77,19 @@ func (ph *PriceCalcHandler) getIngredients(c echo.Context) error {
 		return c.String(http.StatusInternalServerError, "could not get units "+err.Error())
 	}
 
+	allergens, err := ph.service.GetAllergens(c.Request().Context())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get allergens "+err.Error())
+	}
+
+	ingredientAllergens, err := ph.service.GetIngredientAllergens(c.Request().Context())
+	if err != nil {
+		return c.String(
+			http.StatusInternalServerError,
+			"could not get ingredient allergens "+err.Error(),
+		)
+	}
+
 	ph.log.Info("get ingredients", "ingredients", ingredients, "products", products, "units", units)
 
 	// Convert the slice of db.IngredientWithPrices to a slice of viewmodels.IngredientWithPrice
@@ -61,9 +97,12 @@ func (ph *PriceCalcHandler) getIngredients(c echo.Context) error {
 	for i, ingredient := range ingredients {
 		if len(ingredient.Prices) > 0 {
 			ingredientsWithPrice[i] = viewmodels.IngredientWithPrice{
-				ID:    ingredient.Ingredient.ID,
-				Name:  ingredient.Ingredient.Name,
-				Price: ingredient.Prices[0],
+				ID:           ingredient.Ingredient.ID,
+				Name:         ingredient.Ingredient.Name,
+				Abv:          ingredient.Ingredient.Abv,
+				DutyExcluded: ingredient.Ingredient.DutyExcluded,
+				Allergens:    ingredientAllergens[ingredient.Ingredient.ID],
+				Price:        ingredient.Prices[0],
 			}
 		}
 	}
@@ -72,6 +111,7 @@ func (ph *PriceCalcHandler) getIngredients(c echo.Context) error {
 		Ingredients:  ingredientsWithPrice,
 		Units:        units,
 		ProductNames: products,
+		Allergens:    allergens,
 	}
 
 	return render(
@@ -120,6 +160,21 @@ func (ph *PriceCalcHandler) postIngredient(c echo.Context) error {
 		return c.String(http.StatusBadRequest, "could not parse unit id "+err.Error())
 	}
 
+	deposit, err := parseOptionalFloat(c.FormValue("deposit"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse deposit "+err.Error())
+	}
+
+	abv, err := parseNullableFloat(c.FormValue("abv"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse abv "+err.Error())
+	}
+
+	allergens, err := parseAllergenIds(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse allergens "+err.Error())
+	}
+
 	ingredient, err := ph.service.NewIngredient(
 		c.Request().Context(),
 		services.UpdateIngredientParams{
@@ -129,6 +184,10 @@ func (ph *PriceCalcHandler) postIngredient(c echo.Context) error {
 			Quantity:      quantity,
 			UnitID:        unitId,
 			BaseProductID: baseProductId,
+			Deposit:       deposit,
+			Abv:           abv,
+			DutyExcluded:  c.FormValue("duty-excluded") == "on",
+			Allergens:     allergens,
 		},
 	)
 	if err != nil {
@@ -136,9 +195,12 @@ func (ph *PriceCalcHandler) postIngredient(c echo.Context) error {
 	}
 
 	ingredientWithPrice := viewmodels.IngredientWithPrice{
-		ID:    ingredient.Ingredient.ID,
-		Name:  ingredient.Ingredient.Name,
-		Price: ingredient.Prices[0],
+		ID:           ingredient.Ingredient.ID,
+		Name:         ingredient.Ingredient.Name,
+		Abv:          ingredient.Ingredient.Abv,
+		DutyExcluded: ingredient.Ingredient.DutyExcluded,
+		Allergens:    allergens,
+		Price:        ingredient.Prices[0],
 	}
 
 	return render(c, http.StatusCreated, components.NewIngredient(ingredientWithPrice))
@@ -182,6 +244,21 @@ func (ph *PriceCalcHandler) postIngredientPrice(c echo.Context) error {
 		pricePtr = nil
 	}
 
+	deposit, err := parseOptionalFloat(c.FormValue("deposit"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse deposit "+err.Error())
+	}
+
+	abv, err := parseNullableFloat(c.FormValue("abv"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse abv "+err.Error())
+	}
+
+	allergens, err := parseAllergenIds(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse allergens "+err.Error())
+	}
+
 	name := c.FormValue("name")
 
 	_, err = ph.service.UpdateIngredientWithPrice(
@@ -193,6 +270,10 @@ func (ph *PriceCalcHandler) postIngredientPrice(c echo.Context) error {
 			Quantity:      quantity,
 			UnitID:        unitId,
 			BaseProductID: baseProductIdPtr,
+			Deposit:       deposit,
+			Abv:           abv,
+			DutyExcluded:  c.FormValue("duty-excluded") == "on",
+			Allergens:     allergens,
 		},
 	)
 	if err != nil {
@@ -237,23 +318,51 @@ func (ph *PriceCalcHandler) products(c echo.Context) error {
 	if err != nil {
 		return c.String(http.StatusInternalServerError, "could not get ingredients "+err.Error())
 	}
-	categories, err := ph.service.GetCategories()
+	categories, err := ph.service.GetCategoriesWithVat(c.Request().Context(), time.Now())
 	if err != nil {
 		return c.String(http.StatusInternalServerError, "could not get categories "+err.Error())
 	}
+	margins, err := ph.service.GetChannelMargins(c.Request().Context(), products)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get channel margins "+err.Error())
+	}
 	return render(
 		c,
 		http.StatusOK,
-		components.Index(components.ProductsTable(products, categories)),
+		components.Index(components.ProductsTable(products, categories, margins)),
 	)
 }
 
 func (ph *PriceCalcHandler) categories(c echo.Context) error {
-	categories, err := ph.service.GetCategories()
+	categories, err := ph.service.GetCategoriesWithVat(c.Request().Context(), time.Now())
 	if err != nil {
 		return c.String(http.StatusInternalServerError, "could not get categories "+err.Error())
 	}
-	return render(c, http.StatusOK, components.Index(components.Categories(categories)))
+	rates, err := ph.service.GetVatRates(c.Request().Context())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get vat rates "+err.Error())
+	}
+	return render(c, http.StatusOK, components.Index(components.Categories(categories, rates)))
+}
+
+func (ph *PriceCalcHandler) renderCategoryRow(
+	c echo.Context,
+	statusCode int,
+	categoryId int64,
+	edit bool,
+) error {
+	category, err := ph.service.GetCategoryWithVat(c.Request().Context(), categoryId, time.Now())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get category "+err.Error())
+	}
+	rates, err := ph.service.GetVatRates(c.Request().Context())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get vat rates "+err.Error())
+	}
+	if edit {
+		return render(c, statusCode, components.CategoryRowEdit(*category, rates))
+	}
+	return render(c, statusCode, components.CategoryRow(*category, rates))
 }
 
 func (ph *PriceCalcHandler) putCategory(c echo.Context) error {
@@ -262,12 +371,20 @@ func (ph *PriceCalcHandler) putCategory(c echo.Context) error {
 	if err != nil {
 		return c.String(http.StatusBadRequest, "could not parse vat "+err.Error())
 	}
-	category, err := ph.service.PutCategory(name, vat)
+	dineInVatRateId, err := parseOptionalId(c.FormValue("dine-in-vat-rate-id"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse dine-in vat rate id "+err.Error())
+	}
+	takeawayVatRateId, err := parseOptionalId(c.FormValue("takeaway-vat-rate-id"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse takeaway vat rate id "+err.Error())
+	}
+	category, err := ph.service.PutCategory(name, vat, dineInVatRateId, takeawayVatRateId)
 	if err != nil {
 		return c.String(http.StatusInternalServerError, "could not insert category "+err.Error())
 	}
 
-	return render(c, http.StatusOK, components.CategoryRow(*category))
+	return ph.renderCategoryRow(c, http.StatusOK, category.ID, false)
 }
 
 func (ph *PriceCalcHandler) updateCategory(c echo.Context) error {
@@ -280,11 +397,19 @@ func (ph *PriceCalcHandler) updateCategory(c echo.Context) error {
 	if err != nil {
 		return c.String(http.StatusBadRequest, "could not parse category id "+err.Error())
 	}
-	category, err := ph.service.UpdateCategory(categoryId, name, vat)
+	dineInVatRateId, err := parseOptionalId(c.FormValue("dine-in-vat-rate-id"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse dine-in vat rate id "+err.Error())
+	}
+	takeawayVatRateId, err := parseOptionalId(c.FormValue("takeaway-vat-rate-id"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse takeaway vat rate id "+err.Error())
+	}
+	_, err = ph.service.UpdateCategory(categoryId, name, vat, dineInVatRateId, takeawayVatRateId)
 	if err != nil {
 		return c.String(http.StatusInternalServerError, "couold not update category "+err.Error())
 	}
-	return render(c, http.StatusCreated, components.CategoryRow(*category))
+	return ph.renderCategoryRow(c, http.StatusCreated, categoryId, false)
 }
 
 func (ph *PriceCalcHandler) getCategory(c echo.Context) error {
@@ -292,11 +417,7 @@ func (ph *PriceCalcHandler) getCategory(c echo.Context) error {
 	if err != nil {
 		return c.String(http.StatusBadRequest, "could not parse category id "+err.Error())
 	}
-	category, err := ph.service.GetCategory(categoryId)
-	if err != nil {
-		return c.String(http.StatusInternalServerError, "could not get category "+err.Error())
-	}
-	return render(c, http.StatusOK, components.CategoryRow(*category))
+	return ph.renderCategoryRow(c, http.StatusOK, categoryId, false)
 }
 
 func (ph *PriceCalcHandler) getCategoryEdit(c echo.Context) error {
@@ -304,11 +425,7 @@ func (ph *PriceCalcHandler) getCategoryEdit(c echo.Context) error {
 	if err != nil {
 		return c.String(http.StatusBadRequest, "could not parse category id "+err.Error())
 	}
-	category, err := ph.service.GetCategory(categoryId)
-	if err != nil {
-		return c.String(http.StatusInternalServerError, "could not get category "+err.Error())
-	}
-	return render(c, http.StatusOK, components.CategoryRowEdit(*category))
+	return ph.renderCategoryRow(c, http.StatusOK, categoryId, true)
 }
 
 func (ph *PriceCalcHandler) putProduct(c echo.Context) error {
@@ -322,11 +439,22 @@ func (ph *PriceCalcHandler) putProduct(c echo.Context) error {
 		return c.String(http.StatusInternalServerError, "could not insert product "+err.Error())
 	}
 	productWithCost := viewmodels.ProductWithCost{Product: *product, Cost: 0}
-	categories, err := ph.service.GetCategories()
+	categories, err := ph.service.GetCategoriesWithVat(c.Request().Context(), time.Now())
 	if err != nil {
 		return c.String(http.StatusInternalServerError, "could not get categories "+err.Error())
 	}
-	return render(c, http.StatusOK, components.ProductRow(productWithCost, categories))
+	margins, err := ph.service.GetChannelMargins(
+		c.Request().Context(),
+		[]viewmodels.ProductWithCost{productWithCost},
+	)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get channel margins "+err.Error())
+	}
+	return render(
+		c,
+		http.StatusOK,
+		components.ProductRow(productWithCost, categories, margins[product.ID]),
+	)
 }
 
 func (ph *PriceCalcHandler) getProductEditPage(c echo.Context) error {
@@ -346,7 +474,7 @@ func (ph *PriceCalcHandler) getProductEditPage(c echo.Context) error {
 		)
 	}
 
-	categories, err := ph.service.GetCategories()
+	categories, err := ph.service.GetCategoriesWithVat(c.Request().Context(), time.Now())
 	if err != nil {
 		return c.String(http.StatusInternalServerError, "could not get categories "+err.Error())
 	}
@@ -365,12 +493,36 @@ func (ph *PriceCalcHandler) getProductEditPage(c echo.Context) error {
 		return c.String(http.StatusInternalServerError, "could not get units "+err.Error())
 	}
 
+	costSettings, err := ph.service.GetCostSettings(c.Request().Context())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get cost settings "+err.Error())
+	}
+
+	alcohol, err := ph.service.GetProductAlcohol(c.Request().Context(), productId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get alcohol "+err.Error())
+	}
+
+	nutrition, err := ph.service.GetProductNutrition(c.Request().Context(), productId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get nutrition "+err.Error())
+	}
+
+	recipe, err := ph.service.GetRecipe(c.Request().Context(), productId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get recipe "+err.Error())
+	}
+
 	viewModel := viewmodels.ProductEditViewModel{
 		Product:          *productWithCost,
 		Categories:       categories,
 		IngredientUsages: ingredientUsage,
 		Ingredients:      ingredientsMap,
 		Units:            units,
+		CostSettings:     *costSettings,
+		Alcohol:          *alcohol,
+		Nutrition:        *nutrition,
+		Recipe:           *recipe,
 	}
 
 	return render(
@@ -399,11 +551,32 @@ func (ph *PriceCalcHandler) postProduct(c echo.Context) error {
 	if err != nil {
 		return c.String(http.StatusBadRequest, "could not parse multiplicator "+err.Error())
 	}
+	prepMinutes, err := strconv.ParseFloat(c.FormValue("prep-minutes"), 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse preparation time "+err.Error())
+	}
+	deposit, err := parseOptionalFloat(c.FormValue("deposit"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse deposit "+err.Error())
+	}
+	servings, err := strconv.ParseFloat(c.FormValue("servings"), 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse servings "+err.Error())
+	}
 	categoryId, err := strconv.ParseInt(c.FormValue("category"), 10, 64)
 	if err != nil {
 		return c.String(http.StatusBadRequest, "could not parse category id "+err.Error())
 	}
-	_, err = ph.service.UpdateProduct(productId, categoryId, price, multiplicator, name)
+	_, err = ph.service.UpdateProduct(
+		productId,
+		categoryId,
+		price,
+		multiplicator,
+		prepMinutes,
+		deposit,
+		servings,
+		name,
+	)
 	if err != nil {
 		return c.String(http.StatusInternalServerError, "could not update product "+err.Error())
 	}
@@ -415,12 +588,19 @@ func (ph *PriceCalcHandler) postProduct(c echo.Context) error {
 			"could not get updated product "+err.Error(),
 		)
 	}
-	categories, err := ph.service.GetCategories()
+	categories, err := ph.service.GetCategoriesWithVat(c.Request().Context(), time.Now())
 	if err != nil {
 		return c.String(http.StatusInternalServerError, "could not get categories "+err.Error())
 	}
+	margins, err := ph.service.GetChannelMargins(
+		c.Request().Context(),
+		[]viewmodels.ProductWithCost{*product},
+	)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get channel margins "+err.Error())
+	}
 
-	return render(c, http.StatusOK, components.ProductRow(*product, categories))
+	return render(c, http.StatusOK, components.ProductRow(*product, categories, margins[productId]))
 }
 
 func (ph *PriceCalcHandler) deleteProduct(c echo.Context) error {
@@ -429,6 +609,22 @@ func (ph *PriceCalcHandler) deleteProduct(c echo.Context) error {
 		return c.String(http.StatusBadRequest, "could not parse product id "+err.Error())
 	}
 
+	// Check if the product is a component of any bundle
+	bundles, err := ph.service.GetBundlesWithProduct(c.Request().Context(), productId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get bundles "+err.Error())
+	}
+
+	if len(bundles) > 0 {
+		return c.String(
+			http.StatusConflict,
+			"Cannot delete product because its still used in the following bundles:\n"+strings.Join(
+				bundles,
+				", ",
+			),
+		)
+	}
+
 	err = ph.service.DeleteProduct(productId)
 	if err != nil {
 		return c.String(http.StatusBadRequest, "error when deleting product "+err.Error())
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (ph *PriceCalcHandler) getPriceListCsv(c echo.Context) error {
+	entries, err := ph.service.GetPriceList(c.Request().Context(), time.Now())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get price list "+err.Error())
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	c.Response().Header().Set(
+		echo.HeaderContentDisposition,
+		`attachment; filename="price_list.csv"`,
+	)
+	c.Response().WriteHeader(http.StatusOK)
+
+	w := csv.NewWriter(c.Response())
+	err = w.Write([]string{
+		"product", "category", "vat", "price", "net_price",
+		"deposit", "deposit_net", "total", "total_vat", "allergens",
+	})
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		allergens := make([]string, len(entry.Allergens))
+		for i, allergen := range entry.Allergens {
+			allergens[i] = allergen.Code
+		}
+		err = w.Write([]string{
+			entry.Name,
+			entry.Category,
+			formatCsvFloat(entry.Vat),
+			formatCsvFloat(entry.Price),
+			formatCsvFloat(entry.NetPrice),
+			formatCsvFloat(entry.Deposit),
+			formatCsvFloat(entry.DepositNet),
+			formatCsvFloat(entry.Total),
+			formatCsvFloat(entry.TotalVat),
+			strings.Join(allergens, ","),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
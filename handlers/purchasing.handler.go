@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mike-jl/price_calc/components"
+	"github.com/mike-jl/price_calc/services"
+)
+
+func parseParLevelForm(c echo.Context) (*services.ParLevelParams, error) {
+	params := services.ParLevelParams{Supplier: c.FormValue("supplier")}
+	var err error
+	params.IngredientID, err = strconv.ParseInt(c.FormValue("ingredient-id"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	params.UnitID, err = strconv.ParseInt(c.FormValue("unit-id"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	params.Par, err = strconv.ParseFloat(c.FormValue("par"), 64)
+	if err != nil {
+		return nil, err
+	}
+	params.ReorderPoint, err = parseOptionalFloat(c.FormValue("reorder-point"))
+	if err != nil {
+		return nil, err
+	}
+	params.PackSize, err = parseOptionalFloat(c.FormValue("pack-size"))
+	if err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+// parsePurchaseOrderReceipts reads the delivered quantity and the price of
+// every line of the receive form
+func parsePurchaseOrderReceipts(c echo.Context) ([]services.PurchaseOrderReceipt, error) {
+	form, err := c.FormParams()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]services.PurchaseOrderReceipt, 0, len(form["line-id"]))
+	for _, value := range form["line-id"] {
+		lineId, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		receipt := services.PurchaseOrderReceipt{LineID: lineId}
+		receipt.Quantity, err = parseOptionalFloat(form.Get(fmt.Sprintf("quantity-%d", lineId)))
+		if err != nil {
+			return nil, err
+		}
+		receipt.Price, err = parseOptionalFloat(form.Get(fmt.Sprintf("price-%d", lineId)))
+		if err != nil {
+			return nil, err
+		}
+		receipt.Deposit, err = parseOptionalFloat(form.Get(fmt.Sprintf("deposit-%d", lineId)))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, receipt)
+	}
+	return out, nil
+}
+
+func (ph *PriceCalcHandler) renderPurchasing(c echo.Context, statusCode int, page bool) error {
+	purchasing, err := ph.service.GetPurchasing(c.Request().Context())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get purchasing "+err.Error())
+	}
+	if page {
+		return render(c, statusCode, components.Index(components.Purchasing(*purchasing)))
+	}
+	return render(c, statusCode, components.Purchasing(*purchasing))
+}
+
+func (ph *PriceCalcHandler) getPurchasing(c echo.Context) error {
+	return ph.renderPurchasing(c, http.StatusOK, true)
+}
+
+func (ph *PriceCalcHandler) putParLevel(c echo.Context) error {
+	params, err := parseParLevelForm(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse par level "+err.Error())
+	}
+
+	err = ph.service.PutParLevel(c.Request().Context(), *params)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not save par level "+err.Error())
+	}
+	return ph.renderPurchasing(c, http.StatusOK, false)
+}
+
+func (ph *PriceCalcHandler) deleteParLevel(c echo.Context) error {
+	parLevelId, err := strconv.ParseInt(c.Param("par-level-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse par level id "+err.Error())
+	}
+
+	err = ph.service.DeleteParLevel(c.Request().Context(), parLevelId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not delete par level "+err.Error())
+	}
+	return ph.renderPurchasing(c, http.StatusOK, false)
+}
+
+func (ph *PriceCalcHandler) postPurchaseOrders(c echo.Context) error {
+	params := services.PurchaseOrderParams{Forecast: c.FormValue("basis") == "forecast"}
+	if params.Forecast {
+		days, err := strconv.Atoi(c.FormValue("days"))
+		if err != nil {
+			return c.String(http.StatusBadRequest, "could not parse days "+err.Error())
+		}
+		params.Days = days
+	}
+
+	_, err := ph.service.GeneratePurchaseOrders(c.Request().Context(), params, time.Now())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not generate purchase orders "+err.Error())
+	}
+	return ph.renderPurchasing(c, http.StatusOK, false)
+}
+
+func (ph *PriceCalcHandler) deletePurchaseOrder(c echo.Context) error {
+	orderId, err := strconv.ParseInt(c.Param("purchase-order-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse purchase order id "+err.Error())
+	}
+
+	err = ph.service.DeletePurchaseOrder(c.Request().Context(), orderId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not delete purchase order "+err.Error())
+	}
+	return ph.renderPurchasing(c, http.StatusOK, false)
+}
+
+func (ph *PriceCalcHandler) renderPurchaseOrder(
+	c echo.Context,
+	statusCode int,
+	orderId int64,
+	page bool,
+) error {
+	order, err := ph.service.GetPurchaseOrder(c.Request().Context(), orderId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get purchase order "+err.Error())
+	}
+	if page {
+		return render(c, statusCode, components.Index(components.PurchaseOrder(*order)))
+	}
+	return render(c, statusCode, components.PurchaseOrder(*order))
+}
+
+func (ph *PriceCalcHandler) getPurchaseOrder(c echo.Context) error {
+	orderId, err := strconv.ParseInt(c.Param("purchase-order-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse purchase order id "+err.Error())
+	}
+	return ph.renderPurchaseOrder(c, http.StatusOK, orderId, true)
+}
+
+func (ph *PriceCalcHandler) postReceivePurchaseOrder(c echo.Context) error {
+	orderId, err := strconv.ParseInt(c.Param("purchase-order-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse purchase order id "+err.Error())
+	}
+	receivedAt, err := time.Parse(time.DateOnly, c.FormValue("received-at"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse date "+err.Error())
+	}
+	receipts, err := parsePurchaseOrderReceipts(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse receipts "+err.Error())
+	}
+
+	err = ph.service.ReceivePurchaseOrder(c.Request().Context(), orderId, receipts, receivedAt)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not receive purchase order "+err.Error())
+	}
+	return ph.renderPurchaseOrder(c, http.StatusOK, orderId, false)
+}
+
+func (ph *PriceCalcHandler) getPurchaseOrderCsv(c echo.Context) error {
+	orderId, err := strconv.ParseInt(c.Param("purchase-order-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse purchase order id "+err.Error())
+	}
+	order, err := ph.service.GetPurchaseOrder(c.Request().Context(), orderId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get purchase order "+err.Error())
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	c.Response().Header().Set(
+		echo.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="purchase_order_%d.csv"`, orderId),
+	)
+	c.Response().WriteHeader(http.StatusOK)
+
+	w := csv.NewWriter(c.Response())
+	err = w.Write([]string{"supplier", "ingredient", "quantity", "unit", "packs", "expected_cost"})
+	if err != nil {
+		return err
+	}
+	for _, line := range order.Lines {
+		err = w.Write([]string{
+			order.Order.Supplier,
+			line.Name,
+			strconv.FormatFloat(line.Quantity, 'f', -1, 64),
+			line.Unit,
+			strconv.FormatFloat(line.Packs, 'f', -1, 64),
+			formatCsvFloat(line.ExpectedCost),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
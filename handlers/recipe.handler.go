@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mike-jl/price_calc/components"
+)
+
+func (ph *PriceCalcHandler) renderRecipe(
+	c echo.Context,
+	statusCode int,
+	productId int64,
+	page bool,
+) error {
+	recipe, err := ph.service.GetRecipe(c.Request().Context(), productId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get recipe "+err.Error())
+	}
+	if page {
+		return render(c, statusCode, components.RecipeCard(*recipe))
+	}
+	return render(c, statusCode, components.RecipeEdit(*recipe))
+}
+
+func (ph *PriceCalcHandler) getRecipeCard(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("product-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse product id "+err.Error())
+	}
+	return ph.renderRecipe(c, http.StatusOK, productId, true)
+}
+
+func (ph *PriceCalcHandler) postRecipe(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("product-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse product id "+err.Error())
+	}
+
+	err = ph.service.UpdateRecipe(
+		c.Request().Context(),
+		productId,
+		c.FormValue("notes"),
+		c.FormValue("glassware"),
+		c.FormValue("garnish"),
+	)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not update recipe "+err.Error())
+	}
+	return ph.renderRecipe(c, http.StatusOK, productId, false)
+}
+
+func (ph *PriceCalcHandler) putRecipeStep(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("product-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse product id "+err.Error())
+	}
+
+	err = ph.service.AddRecipeStep(c.Request().Context(), productId, c.FormValue("text"))
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not add step "+err.Error())
+	}
+	return ph.renderRecipe(c, http.StatusCreated, productId, false)
+}
+
+func (ph *PriceCalcHandler) postRecipeStep(c echo.Context) error {
+	stepId, err := strconv.ParseInt(c.Param("recipe-step-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse step id "+err.Error())
+	}
+
+	productId, err := ph.service.UpdateRecipeStep(c.Request().Context(), stepId, c.FormValue("text"))
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not update step "+err.Error())
+	}
+	return ph.renderRecipe(c, http.StatusOK, productId, false)
+}
+
+func (ph *PriceCalcHandler) postRecipeStepMove(c echo.Context) error {
+	stepId, err := strconv.ParseInt(c.Param("recipe-step-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse step id "+err.Error())
+	}
+
+	var offset int
+	switch c.QueryParam("direction") {
+	case "up":
+		offset = -1
+	case "down":
+		offset = 1
+	default:
+		return c.String(http.StatusBadRequest, "invalid direction "+c.QueryParam("direction"))
+	}
+
+	productId, err := ph.service.MoveRecipeStep(c.Request().Context(), stepId, offset)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not move step "+err.Error())
+	}
+	return ph.renderRecipe(c, http.StatusOK, productId, false)
+}
+
+func (ph *PriceCalcHandler) deleteRecipeStep(c echo.Context) error {
+	stepId, err := strconv.ParseInt(c.Param("recipe-step-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse step id "+err.Error())
+	}
+
+	productId, err := ph.service.DeleteRecipeStep(c.Request().Context(), stepId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not delete step "+err.Error())
+	}
+	return ph.renderRecipe(c, http.StatusOK, productId, false)
+}
+
+func (ph *PriceCalcHandler) putProductPhoto(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("product-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse product id "+err.Error())
+	}
+	fileHeader, err := c.FormFile("photo")
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not read photo "+err.Error())
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not read photo "+err.Error())
+	}
+	defer file.Close()
+
+	err = ph.service.AddProductPhoto(c.Request().Context(), productId, file)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not save photo "+err.Error())
+	}
+	return ph.renderRecipe(c, http.StatusCreated, productId, false)
+}
+
+func (ph *PriceCalcHandler) getProductPhoto(c echo.Context) error {
+	photoId, err := strconv.ParseInt(c.Param("photo-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse photo id "+err.Error())
+	}
+	path, err := ph.service.GetProductPhotoPath(c.Request().Context(), photoId)
+	if err != nil {
+		return c.String(http.StatusNotFound, "could not get photo "+err.Error())
+	}
+	return c.File(path)
+}
+
+func (ph *PriceCalcHandler) deleteProductPhoto(c echo.Context) error {
+	photoId, err := strconv.ParseInt(c.Param("photo-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse photo id "+err.Error())
+	}
+
+	productId, err := ph.service.DeleteProductPhoto(c.Request().Context(), photoId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not delete photo "+err.Error())
+	}
+	return ph.renderRecipe(c, http.StatusOK, productId, false)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mike-jl/price_calc/components"
+	"github.com/mike-jl/price_calc/services"
+)
+
+func parseReverseCostingParams(c echo.Context) (*services.ReverseCostingParams, error) {
+	params := services.ReverseCostingParams{}
+	var err error
+	params.GrossPrice, err = parseOptionalFloat(c.QueryParam("price"))
+	if err != nil {
+		return nil, err
+	}
+	categoryId, err := parseOptionalId(c.QueryParam("category-id"))
+	if err != nil {
+		return nil, err
+	}
+	if categoryId != nil {
+		params.CategoryID = *categoryId
+	}
+	params.Multiplicator, err = parseOptionalFloat(c.QueryParam("multiplicator"))
+	if err != nil {
+		return nil, err
+	}
+	params.CostRatio, err = parseOptionalFloat(c.QueryParam("cost-ratio"))
+	if err != nil {
+		return nil, err
+	}
+	params.ProductID, err = parseOptionalId(c.QueryParam("product-id"))
+	if err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+func (ph *PriceCalcHandler) getReverseCosting(c echo.Context) error {
+	params, err := parseReverseCostingParams(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse target price "+err.Error())
+	}
+
+	reverse, err := ph.service.GetReverseCosting(c.Request().Context(), *params)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not calculate budget "+err.Error())
+	}
+	return render(c, http.StatusOK, components.Index(components.ReverseCosting(*reverse)))
+}
+
+func (ph *PriceCalcHandler) getReverseCostingJson(c echo.Context) error {
+	params, err := parseReverseCostingParams(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse target price "+err.Error())
+	}
+
+	reverse, err := ph.service.GetReverseCosting(c.Request().Context(), *params)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not calculate budget "+err.Error())
+	}
+	return c.JSON(http.StatusOK, reverse)
+}
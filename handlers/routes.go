@@ -7,16 +7,108 @@ func SetupRoutes(e *echo.Echo, ph *PriceCalcHandler) {
 	e.POST("/ingredient", ph.postIngredient)
 	e.POST("/ingredient-price/:ingredient-id", ph.postIngredientPrice)
 	e.DELETE("/ingredient/:ingredient-id", ph.deleteIngredient)
+	e.GET("/ingredient/:ingredient-id/where-used", ph.getIngredientWhereUsed)
 	e.GET("/categories", ph.categories)
 	e.GET("/products", ph.products)
+	e.GET("/products.csv", ph.getPriceListCsv)
 	e.PUT("/category", ph.putCategory)
 	e.GET("/category/:category-id", ph.getCategory)
 	e.GET("/category/:category-id/edit", ph.getCategoryEdit)
 	e.PUT("/category/:category-id", ph.updateCategory)
+	e.GET("/vat-rates", ph.getVatRates)
+	e.PUT("/vat-rate", ph.putVatRate)
+	e.PUT("/vat-rate/:vat-rate-id/period", ph.putVatRatePeriod)
+	e.DELETE("/vat-rate-period/:vat-rate-period-id", ph.deleteVatRatePeriod)
+	e.GET("/channels", ph.getChannels)
+	e.PUT("/channel", ph.putChannel)
+	e.POST("/channel/:channel-id", ph.postChannel)
+	e.DELETE("/channel/:channel-id", ph.deleteChannel)
+	e.GET("/reports/channels", ph.getChannelReport)
+	e.GET("/reports/channels.csv", ph.getChannelReportCsv)
+	e.GET("/costing", ph.getCosting)
+	e.POST("/cost-settings", ph.postCostSettings)
+	e.PUT("/overhead-rule", ph.putOverheadRule)
+	e.DELETE("/overhead-rule/:overhead-rule-id", ph.deleteOverheadRule)
+	e.GET("/substitution", ph.getSubstitution)
+	e.GET("/substitution/preview", ph.getSubstitutionPreview)
+	e.POST("/substitution", ph.postSubstitution)
+	e.GET("/reverse-costing", ph.getReverseCosting)
+	e.GET("/reverse-costing.json", ph.getReverseCostingJson)
+	e.GET("/goods-receipts", ph.getGoodsReceipts)
+	e.PUT("/goods-receipt", ph.putGoodsReceipt)
+	e.DELETE("/goods-receipt/:goods-receipt-id", ph.deleteGoodsReceipt)
+	e.GET("/stock", ph.getStock)
+	e.GET("/stocktakes", ph.getStocktakes)
+	e.PUT("/stocktake", ph.putStocktake)
+	e.GET("/stocktake/:stocktake-id", ph.getStocktake)
+	e.DELETE("/stocktake/:stocktake-id", ph.deleteStocktake)
+	e.PUT("/stocktake/:stocktake-id/count", ph.putStocktakeCount)
+	e.POST("/stocktake/:stocktake-id/finish", ph.postFinishStocktake)
+	e.DELETE("/stocktake-count/:stocktake-count-id", ph.deleteStocktakeCount)
+	e.GET("/sales-import", ph.getPosImport)
+	e.POST("/sales-import", ph.postPosImport)
+	e.POST("/pos-settings", ph.postPosSettings)
+	e.PUT("/pos-item/:pos-item-id", ph.putPosItem)
+	e.DELETE("/pos-item/:pos-item-id", ph.deletePosItem)
+	e.GET("/sales", ph.getSales)
+	e.PUT("/sales", ph.putSales)
+	e.GET("/consumption", ph.getConsumption)
+	e.GET("/consumption.csv", ph.getConsumptionCsv)
+	e.GET("/consumption.json", ph.getConsumptionJson)
+	e.GET("/menu-engineering", ph.getMenuEngineering)
+	e.GET("/menu-engineering.csv", ph.getMenuEngineeringCsv)
+	e.GET("/menu-engineering.json", ph.getMenuEngineeringJson)
+	e.GET("/purchasing", ph.getPurchasing)
+	e.PUT("/par-level", ph.putParLevel)
+	e.DELETE("/par-level/:par-level-id", ph.deleteParLevel)
+	e.POST("/purchase-orders", ph.postPurchaseOrders)
+	e.GET("/purchase-order/:purchase-order-id", ph.getPurchaseOrder)
+	e.DELETE("/purchase-order/:purchase-order-id", ph.deletePurchaseOrder)
+	e.POST("/purchase-order/:purchase-order-id/receive", ph.postReceivePurchaseOrder)
+	e.GET("/purchase-order/:purchase-order-id/order.csv", ph.getPurchaseOrderCsv)
+	e.GET("/waste", ph.getWaste)
+	e.PUT("/waste", ph.putWaste)
+	e.DELETE("/waste/:waste-id", ph.deleteWaste)
+	e.GET("/comps", ph.getComps)
+	e.PUT("/comps", ph.putComp)
+	e.DELETE("/comps/:comp-id", ph.deleteComp)
+	e.GET("/scenarios", ph.getScenarios)
+	e.PUT("/scenario", ph.putScenario)
+	e.GET("/scenario/:scenario-id", ph.getScenario)
+	e.DELETE("/scenario/:scenario-id", ph.deleteScenario)
+	e.PUT("/scenario/:scenario-id/override", ph.putScenarioOverride)
+	e.POST("/scenario/:scenario-id/commit", ph.postScenarioCommit)
+	e.DELETE("/scenario-override/:scenario-override-id", ph.deleteScenarioOverride)
+	e.GET("/nutrition", ph.getNutrition)
+	e.PUT("/ingredient/:ingredient-id/nutrition", ph.putIngredientNutrition)
 	e.PUT("/product", ph.putProduct)
 	e.GET("/product/:product-id/edit", ph.getProductEditPage)
 	e.POST("/product/:product-id", ph.postProduct)
 	e.DELETE("/product/:product-id", ph.deleteProduct)
+	e.GET("/product/:product-id/bundle", ph.getBundleEditPage)
+	e.GET("/product/:product-id/channels", ph.getProductChannels)
+	e.GET("/product/:product-id/alcohol", ph.getProductAlcohol)
+	e.GET("/product/:product-id/nutrition", ph.getProductNutrition)
+	e.GET("/product/:product-id/cost-tree", ph.getProductCostTree)
+	e.GET("/product/:product-id/cost-tree.json", ph.getProductCostTreeJson)
+	e.GET("/product/:product-id/where-used", ph.getProductWhereUsed)
+	e.GET("/product/:product-id/recipe", ph.getRecipeCard)
+	e.GET("/product/:product-id/scale", ph.getScaledRecipe)
+	e.GET("/product/:product-id/scale.json", ph.getScaledRecipeJson)
+	e.POST("/product/:product-id/recipe", ph.postRecipe)
+	e.PUT("/product/:product-id/recipe-step", ph.putRecipeStep)
+	e.POST("/recipe-step/:recipe-step-id", ph.postRecipeStep)
+	e.POST("/recipe-step/:recipe-step-id/move", ph.postRecipeStepMove)
+	e.DELETE("/recipe-step/:recipe-step-id", ph.deleteRecipeStep)
+	e.PUT("/product/:product-id/photo", ph.putProductPhoto)
+	e.GET("/photo/:photo-id", ph.getProductPhoto)
+	e.DELETE("/photo/:photo-id", ph.deleteProductPhoto)
+	e.POST("/product/:product-id/channel/:channel-id", ph.postProductChannelPrice)
+	e.PUT("/product/:product-id/channel/:channel-id/packaging", ph.putChannelPackaging)
+	e.DELETE("/product/:product-id/packaging/:packaging-id", ph.deleteChannelPackaging)
+	e.PUT("/bundle-component/:product-id", ph.putBundleComponent)
+	e.POST("/bundle-component/:bundle-component-id", ph.postBundleComponent)
+	e.DELETE("/bundle-component/:bundle-component-id", ph.deleteBundleComponent)
 	e.PUT("/ingredient-usage/:product-id", ph.putIngredientUsage)
 	e.GET("/ingredient-usage-edit/:ingredient-usage-id", ph.getIngredientUsageEdit)
 	e.POST("/ingredient-usage/:ingredient-usage-id", ph.postIngredientUsage)
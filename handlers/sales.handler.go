@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mike-jl/price_calc/components"
+	"github.com/mike-jl/price_calc/services"
+)
+
+// parseSalesDay reads the day of a sales entry, an empty value is today
+func parseSalesDay(value string) (time.Time, error) {
+	if value == "" {
+		return time.Now(), nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+// parseManualSales reads the quantity and revenue of every product row of
+// the sales entry form, rows without a quantity are sent as 0
+func parseManualSales(c echo.Context) ([]services.ManualSale, error) {
+	form, err := c.FormParams()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]services.ManualSale, 0, len(form["product-id"]))
+	for _, value := range form["product-id"] {
+		productId, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		sale := services.ManualSale{ProductID: productId}
+		sale.Quantity, err = parseOptionalFloat(form.Get(fmt.Sprintf("quantity-%d", productId)))
+		if err != nil {
+			return nil, err
+		}
+		sale.Revenue, err = parseNullableFloat(form.Get(fmt.Sprintf("revenue-%d", productId)))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sale)
+	}
+	return out, nil
+}
+
+func (ph *PriceCalcHandler) renderSalesEntry(
+	c echo.Context,
+	statusCode int,
+	day time.Time,
+	shift string,
+	page bool,
+) error {
+	entry, err := ph.service.GetSalesEntry(c.Request().Context(), day, shift)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get sales "+err.Error())
+	}
+	if page {
+		return render(c, statusCode, components.Index(components.SalesEntry(*entry)))
+	}
+	return render(c, statusCode, components.SalesEntry(*entry))
+}
+
+func (ph *PriceCalcHandler) getSales(c echo.Context) error {
+	day, err := parseSalesDay(c.QueryParam("day"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse day "+err.Error())
+	}
+	return ph.renderSalesEntry(c, http.StatusOK, day, c.QueryParam("shift"), true)
+}
+
+func (ph *PriceCalcHandler) putSales(c echo.Context) error {
+	day, err := parseSalesDay(c.FormValue("day"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse day "+err.Error())
+	}
+	sales, err := parseManualSales(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse sales "+err.Error())
+	}
+
+	shift := c.FormValue("shift")
+	err = ph.service.PutManualSales(c.Request().Context(), day, shift, sales)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not save sales "+err.Error())
+	}
+	return ph.renderSalesEntry(c, http.StatusOK, day, shift, false)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mike-jl/price_calc/components"
+	"github.com/mike-jl/price_calc/services"
+)
+
+func parseScaleParams(c echo.Context) (*services.ScaleParams, error) {
+	params := services.ScaleParams{Factor: 1, Expand: c.QueryParam("expand") == "on"}
+	var err error
+	if c.QueryParam("factor") != "" {
+		params.Factor, err = strconv.ParseFloat(c.QueryParam("factor"), 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+	params.Servings, err = parseOptionalFloat(c.QueryParam("servings"))
+	if err != nil {
+		return nil, err
+	}
+	params.Yield, err = parseOptionalFloat(c.QueryParam("yield"))
+	if err != nil {
+		return nil, err
+	}
+	if params.Yield != 0 {
+		params.YieldUnitID, err = strconv.ParseInt(c.QueryParam("unit"), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &params, nil
+}
+
+func (ph *PriceCalcHandler) getScaledRecipe(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("product-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse product id "+err.Error())
+	}
+	params, err := parseScaleParams(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse scale "+err.Error())
+	}
+
+	scaled, err := ph.service.ScaleRecipe(c.Request().Context(), productId, *params)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not scale recipe "+err.Error())
+	}
+	return render(c, http.StatusOK, components.Index(components.ScaledRecipe(*scaled)))
+}
+
+func (ph *PriceCalcHandler) getScaledRecipeJson(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("product-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse product id "+err.Error())
+	}
+	params, err := parseScaleParams(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse scale "+err.Error())
+	}
+
+	scaled, err := ph.service.ScaleRecipe(c.Request().Context(), productId, *params)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not scale recipe "+err.Error())
+	}
+	return c.JSON(http.StatusOK, scaled)
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mike-jl/price_calc/components"
+	"github.com/mike-jl/price_calc/services"
+)
+
+func (ph *PriceCalcHandler) renderScenarios(c echo.Context, statusCode int, page bool) error {
+	scenarios, err := ph.service.GetScenarios(c.Request().Context())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get scenarios "+err.Error())
+	}
+	if page {
+		return render(c, statusCode, components.Index(components.Scenarios(scenarios)))
+	}
+	return render(c, statusCode, components.Scenarios(scenarios))
+}
+
+func (ph *PriceCalcHandler) getScenarios(c echo.Context) error {
+	return ph.renderScenarios(c, http.StatusOK, true)
+}
+
+func (ph *PriceCalcHandler) putScenario(c echo.Context) error {
+	_, err := ph.service.PutScenario(c.Request().Context(), c.FormValue("name"))
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not create scenario "+err.Error())
+	}
+	return ph.renderScenarios(c, http.StatusOK, false)
+}
+
+func (ph *PriceCalcHandler) deleteScenario(c echo.Context) error {
+	scenarioId, err := strconv.ParseInt(c.Param("scenario-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse scenario id "+err.Error())
+	}
+
+	err = ph.service.DeleteScenario(c.Request().Context(), scenarioId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not delete scenario "+err.Error())
+	}
+	return ph.renderScenarios(c, http.StatusOK, false)
+}
+
+func (ph *PriceCalcHandler) renderScenario(
+	c echo.Context,
+	statusCode int,
+	scenarioId int64,
+	page bool,
+) error {
+	scenario, err := ph.service.GetScenario(c.Request().Context(), scenarioId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get scenario "+err.Error())
+	}
+	if page {
+		return render(c, statusCode, components.Index(components.Scenario(*scenario)))
+	}
+	return render(c, statusCode, components.Scenario(*scenario))
+}
+
+func (ph *PriceCalcHandler) getScenario(c echo.Context) error {
+	scenarioId, err := strconv.ParseInt(c.Param("scenario-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse scenario id "+err.Error())
+	}
+	return ph.renderScenario(c, http.StatusOK, scenarioId, true)
+}
+
+func (ph *PriceCalcHandler) putScenarioOverride(c echo.Context) error {
+	scenarioId, err := strconv.ParseInt(c.Param("scenario-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse scenario id "+err.Error())
+	}
+	ingredientId, err := strconv.ParseInt(c.FormValue("ingredient-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse ingredient id "+err.Error())
+	}
+	amount, err := strconv.ParseFloat(c.FormValue("amount"), 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse amount "+err.Error())
+	}
+
+	err = ph.service.PutScenarioOverride(
+		c.Request().Context(),
+		scenarioId,
+		ingredientId,
+		services.ScenarioOverrideKind(c.FormValue("kind")),
+		amount,
+	)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not save override "+err.Error())
+	}
+	return ph.renderScenario(c, http.StatusOK, scenarioId, false)
+}
+
+func (ph *PriceCalcHandler) deleteScenarioOverride(c echo.Context) error {
+	overrideId, err := strconv.ParseInt(c.Param("scenario-override-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse override id "+err.Error())
+	}
+
+	scenarioId, err := ph.service.DeleteScenarioOverride(c.Request().Context(), overrideId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not delete override "+err.Error())
+	}
+	return ph.renderScenario(c, http.StatusOK, scenarioId, false)
+}
+
+func (ph *PriceCalcHandler) postScenarioCommit(c echo.Context) error {
+	scenarioId, err := strconv.ParseInt(c.Param("scenario-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse scenario id "+err.Error())
+	}
+
+	err = ph.service.CommitScenario(c.Request().Context(), scenarioId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not commit scenario "+err.Error())
+	}
+	return ph.renderScenario(c, http.StatusOK, scenarioId, false)
+}
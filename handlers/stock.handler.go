@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mike-jl/price_calc/components"
+	"github.com/mike-jl/price_calc/services"
+)
+
+func (ph *PriceCalcHandler) renderGoodsReceipts(c echo.Context, statusCode int, page bool) error {
+	receipts, err := ph.service.GetGoodsReceipts(c.Request().Context())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get goods receipts "+err.Error())
+	}
+	if page {
+		return render(c, statusCode, components.Index(components.GoodsReceipts(*receipts)))
+	}
+	return render(c, statusCode, components.GoodsReceipts(*receipts))
+}
+
+func (ph *PriceCalcHandler) getGoodsReceipts(c echo.Context) error {
+	return ph.renderGoodsReceipts(c, http.StatusOK, true)
+}
+
+func (ph *PriceCalcHandler) putGoodsReceipt(c echo.Context) error {
+	ingredientId, err := strconv.ParseInt(c.FormValue("ingredient-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse ingredient id "+err.Error())
+	}
+	quantity, err := strconv.ParseFloat(c.FormValue("quantity"), 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse quantity "+err.Error())
+	}
+	unitId, err := strconv.ParseInt(c.FormValue("unit-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse unit id "+err.Error())
+	}
+	price, err := strconv.ParseFloat(c.FormValue("price"), 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse price "+err.Error())
+	}
+	deposit, err := parseOptionalFloat(c.FormValue("deposit"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse deposit "+err.Error())
+	}
+	receivedAt, err := time.Parse(time.DateOnly, c.FormValue("received-at"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse date "+err.Error())
+	}
+
+	err = ph.service.ReceiveGoods(c.Request().Context(), services.GoodsReceiptParams{
+		IngredientID: ingredientId,
+		Quantity:     quantity,
+		UnitID:       unitId,
+		Price:        price,
+		Deposit:      deposit,
+		Supplier:     c.FormValue("supplier"),
+		ReceivedAt:   receivedAt,
+	})
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not receive goods "+err.Error())
+	}
+	return ph.renderGoodsReceipts(c, http.StatusOK, false)
+}
+
+func (ph *PriceCalcHandler) deleteGoodsReceipt(c echo.Context) error {
+	receiptId, err := strconv.ParseInt(c.Param("goods-receipt-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse goods receipt id "+err.Error())
+	}
+
+	err = ph.service.DeleteGoodsReceipt(c.Request().Context(), receiptId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not delete goods receipt "+err.Error())
+	}
+	return ph.renderGoodsReceipts(c, http.StatusOK, false)
+}
+
+func (ph *PriceCalcHandler) getStock(c echo.Context) error {
+	stock, err := ph.service.GetStock(c.Request().Context())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get stock "+err.Error())
+	}
+	return render(c, http.StatusOK, components.Stock(*stock))
+}
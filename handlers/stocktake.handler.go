@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mike-jl/price_calc/components"
+	"github.com/mike-jl/price_calc/services"
+)
+
+func (ph *PriceCalcHandler) renderStocktakes(c echo.Context, statusCode int, page bool) error {
+	stocktakes, err := ph.service.GetStocktakes(c.Request().Context())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get stocktakes "+err.Error())
+	}
+	if page {
+		return render(c, statusCode, components.Index(components.Stocktakes(stocktakes)))
+	}
+	return render(c, statusCode, components.Stocktakes(stocktakes))
+}
+
+func (ph *PriceCalcHandler) getStocktakes(c echo.Context) error {
+	return ph.renderStocktakes(c, http.StatusOK, true)
+}
+
+func (ph *PriceCalcHandler) putStocktake(c echo.Context) error {
+	countedAt, err := time.Parse(time.DateOnly, c.FormValue("counted-at"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse date "+err.Error())
+	}
+	_, err = ph.service.PutStocktake(c.Request().Context(), countedAt)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not create stocktake "+err.Error())
+	}
+	return ph.renderStocktakes(c, http.StatusOK, false)
+}
+
+func (ph *PriceCalcHandler) deleteStocktake(c echo.Context) error {
+	stocktakeId, err := strconv.ParseInt(c.Param("stocktake-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse stocktake id "+err.Error())
+	}
+
+	err = ph.service.DeleteStocktake(c.Request().Context(), stocktakeId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not delete stocktake "+err.Error())
+	}
+	return ph.renderStocktakes(c, http.StatusOK, false)
+}
+
+func (ph *PriceCalcHandler) renderStocktake(
+	c echo.Context,
+	statusCode int,
+	stocktakeId int64,
+	page bool,
+) error {
+	threshold := services.DefaultShrinkageThreshold
+	if c.QueryParam("threshold") != "" {
+		var err error
+		threshold, err = strconv.ParseFloat(c.QueryParam("threshold"), 64)
+		if err != nil {
+			return c.String(http.StatusBadRequest, "could not parse threshold "+err.Error())
+		}
+	}
+
+	stocktake, err := ph.service.GetStocktake(c.Request().Context(), stocktakeId, threshold)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get stocktake "+err.Error())
+	}
+	if page {
+		return render(c, statusCode, components.Index(components.Stocktake(*stocktake)))
+	}
+	return render(c, statusCode, components.Stocktake(*stocktake))
+}
+
+func (ph *PriceCalcHandler) getStocktake(c echo.Context) error {
+	stocktakeId, err := strconv.ParseInt(c.Param("stocktake-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse stocktake id "+err.Error())
+	}
+	return ph.renderStocktake(c, http.StatusOK, stocktakeId, true)
+}
+
+func (ph *PriceCalcHandler) putStocktakeCount(c echo.Context) error {
+	stocktakeId, err := strconv.ParseInt(c.Param("stocktake-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse stocktake id "+err.Error())
+	}
+	ingredientId, err := strconv.ParseInt(c.FormValue("ingredient-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse ingredient id "+err.Error())
+	}
+	quantity, err := strconv.ParseFloat(c.FormValue("quantity"), 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse quantity "+err.Error())
+	}
+	unitId, err := strconv.ParseInt(c.FormValue("unit-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse unit id "+err.Error())
+	}
+
+	err = ph.service.PutStocktakeCount(
+		c.Request().Context(),
+		stocktakeId,
+		ingredientId,
+		quantity,
+		unitId,
+	)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not set count "+err.Error())
+	}
+	return ph.renderStocktake(c, http.StatusOK, stocktakeId, false)
+}
+
+func (ph *PriceCalcHandler) deleteStocktakeCount(c echo.Context) error {
+	countId, err := strconv.ParseInt(c.Param("stocktake-count-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse count id "+err.Error())
+	}
+
+	stocktakeId, err := ph.service.DeleteStocktakeCount(c.Request().Context(), countId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not delete count "+err.Error())
+	}
+	return ph.renderStocktake(c, http.StatusOK, stocktakeId, false)
+}
+
+func (ph *PriceCalcHandler) postFinishStocktake(c echo.Context) error {
+	stocktakeId, err := strconv.ParseInt(c.Param("stocktake-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse stocktake id "+err.Error())
+	}
+
+	err = ph.service.FinishStocktake(c.Request().Context(), stocktakeId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not finish stocktake "+err.Error())
+	}
+	return ph.renderStocktake(c, http.StatusOK, stocktakeId, false)
+}
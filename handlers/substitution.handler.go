@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mike-jl/price_calc/components"
+	"github.com/mike-jl/price_calc/internal/utils"
+)
+
+type substitutionParams struct {
+	fromId int64
+	toId   int64
+	ratio  float64
+}
+
+func parseSubstitutionParams(c echo.Context) (*substitutionParams, error) {
+	fromId, err := parseOptionalId(c.FormValue("from"))
+	if err != nil {
+		return nil, err
+	}
+	toId, err := parseOptionalId(c.FormValue("to"))
+	if err != nil {
+		return nil, err
+	}
+	ratio := 1.0
+	if c.FormValue("ratio") != "" {
+		ratio, err = strconv.ParseFloat(c.FormValue("ratio"), 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &substitutionParams{fromId: utils.Deref(fromId), toId: utils.Deref(toId), ratio: ratio}, nil
+}
+
+func (ph *PriceCalcHandler) renderSubstitution(
+	c echo.Context,
+	statusCode int,
+	params substitutionParams,
+	applied *int64,
+	page bool,
+) error {
+	viewModel, err := ph.service.GetSubstitution(
+		c.Request().Context(),
+		params.fromId,
+		params.toId,
+		params.ratio,
+	)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get substitution "+err.Error())
+	}
+	viewModel.Applied = applied
+	if page {
+		return render(c, statusCode, components.Index(components.Substitution(*viewModel)))
+	}
+	return render(c, statusCode, components.Substitution(*viewModel))
+}
+
+func (ph *PriceCalcHandler) getSubstitution(c echo.Context) error {
+	params, err := parseSubstitutionParams(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse substitution "+err.Error())
+	}
+	return ph.renderSubstitution(c, http.StatusOK, *params, nil, true)
+}
+
+func (ph *PriceCalcHandler) getSubstitutionPreview(c echo.Context) error {
+	params, err := parseSubstitutionParams(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse substitution "+err.Error())
+	}
+	return ph.renderSubstitution(c, http.StatusOK, *params, nil, false)
+}
+
+func (ph *PriceCalcHandler) postSubstitution(c echo.Context) error {
+	params, err := parseSubstitutionParams(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse substitution "+err.Error())
+	}
+
+	applied, err := ph.service.ApplySubstitution(
+		c.Request().Context(),
+		params.fromId,
+		params.toId,
+		params.ratio,
+	)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not apply substitution "+err.Error())
+	}
+	return ph.renderSubstitution(c, http.StatusOK, *params, &applied, false)
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mike-jl/price_calc/components"
+)
+
+// parseOptionalId parses an id from a select, where 0 or an empty value means none.
+func parseOptionalId(value string) (*int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	if id == 0 {
+		return nil, nil
+	}
+	return &id, nil
+}
+
+func (ph *PriceCalcHandler) renderVatRates(c echo.Context, statusCode int) error {
+	rates, err := ph.service.GetVatRates(c.Request().Context())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get vat rates "+err.Error())
+	}
+	return render(c, statusCode, components.VatRates(rates))
+}
+
+func (ph *PriceCalcHandler) getVatRates(c echo.Context) error {
+	rates, err := ph.service.GetVatRates(c.Request().Context())
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get vat rates "+err.Error())
+	}
+	return render(c, http.StatusOK, components.Index(components.VatRates(rates)))
+}
+
+func (ph *PriceCalcHandler) putVatRate(c echo.Context) error {
+	name := strings.TrimSpace(c.FormValue("name"))
+	if name == "" {
+		return c.String(http.StatusBadRequest, "vat rate name is empty")
+	}
+	_, err := ph.service.PutVatRate(c.Request().Context(), name)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not insert vat rate "+err.Error())
+	}
+	return ph.renderVatRates(c, http.StatusOK)
+}
+
+func (ph *PriceCalcHandler) putVatRatePeriod(c echo.Context) error {
+	vatRateId, err := strconv.ParseInt(c.Param("vat-rate-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse vat rate id "+err.Error())
+	}
+	rate, err := strconv.ParseFloat(c.FormValue("rate"), 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse rate "+err.Error())
+	}
+	if rate < 0 {
+		return c.String(http.StatusBadRequest, "rate must not be negative")
+	}
+	validFrom, err := time.Parse(time.DateOnly, c.FormValue("valid-from"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse valid from date "+err.Error())
+	}
+	_, err = ph.service.PutVatRatePeriod(c.Request().Context(), vatRateId, rate, validFrom)
+	if err != nil {
+		return c.String(
+			http.StatusInternalServerError,
+			"could not insert vat rate period "+err.Error(),
+		)
+	}
+	return ph.renderVatRates(c, http.StatusOK)
+}
+
+func (ph *PriceCalcHandler) deleteVatRatePeriod(c echo.Context) error {
+	periodId, err := strconv.ParseInt(c.Param("vat-rate-period-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse vat rate period id "+err.Error())
+	}
+	err = ph.service.DeleteVatRatePeriod(c.Request().Context(), periodId)
+	if err != nil {
+		return c.String(
+			http.StatusInternalServerError,
+			"could not delete vat rate period "+err.Error(),
+		)
+	}
+	return ph.renderVatRates(c, http.StatusOK)
+}
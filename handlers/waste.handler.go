@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mike-jl/price_calc/components"
+	"github.com/mike-jl/price_calc/services"
+)
+
+// parseWasteForm reads the wasted item, which is "ingredient-<id>" or
+// "product-<id>"
+func parseWasteForm(c echo.Context) (*services.WasteParams, error) {
+	params := services.WasteParams{
+		Reason: c.FormValue("reason"),
+		Staff:  c.FormValue("staff"),
+	}
+	kind, value, found := strings.Cut(c.FormValue("item"), "-")
+	if !found {
+		return nil, fmt.Errorf("invalid item %q", c.FormValue("item"))
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case "ingredient":
+		params.IngredientID = &id
+	case "product":
+		params.ProductID = &id
+	default:
+		return nil, fmt.Errorf("invalid item %q", c.FormValue("item"))
+	}
+	params.Quantity, err = strconv.ParseFloat(c.FormValue("quantity"), 64)
+	if err != nil {
+		return nil, err
+	}
+	params.UnitID, err = parseOptionalId(c.FormValue("unit-id"))
+	if err != nil {
+		return nil, err
+	}
+	params.WastedOn, err = time.Parse(time.DateOnly, c.FormValue("wasted-on"))
+	if err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+func (ph *PriceCalcHandler) renderWaste(c echo.Context, statusCode int, page bool) error {
+	from, to, err := parseSalesPeriod(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse period "+err.Error())
+	}
+	waste, err := ph.service.GetWaste(c.Request().Context(), from, to)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get waste "+err.Error())
+	}
+	if page {
+		return render(c, statusCode, components.Index(components.Waste(*waste)))
+	}
+	return render(c, statusCode, components.Waste(*waste))
+}
+
+func (ph *PriceCalcHandler) getWaste(c echo.Context) error {
+	return ph.renderWaste(c, http.StatusOK, true)
+}
+
+func (ph *PriceCalcHandler) putWaste(c echo.Context) error {
+	params, err := parseWasteForm(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse waste "+err.Error())
+	}
+
+	err = ph.service.LogWaste(c.Request().Context(), *params)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not log waste "+err.Error())
+	}
+	return ph.renderWaste(c, http.StatusOK, false)
+}
+
+func (ph *PriceCalcHandler) deleteWaste(c echo.Context) error {
+	wasteId, err := strconv.ParseInt(c.Param("waste-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse waste id "+err.Error())
+	}
+
+	err = ph.service.DeleteWaste(c.Request().Context(), wasteId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not delete waste "+err.Error())
+	}
+	return ph.renderWaste(c, http.StatusOK, false)
+}
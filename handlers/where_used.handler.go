@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mike-jl/price_calc/components"
+)
+
+func (ph *PriceCalcHandler) getIngredientWhereUsed(c echo.Context) error {
+	ingredientId, err := strconv.ParseInt(c.Param("ingredient-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse ingredient id "+err.Error())
+	}
+
+	whereUsed, err := ph.service.GetIngredientWhereUsed(c.Request().Context(), ingredientId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get where used "+err.Error())
+	}
+	return render(c, http.StatusOK, components.Index(components.WhereUsed(*whereUsed)))
+}
+
+func (ph *PriceCalcHandler) getProductWhereUsed(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("product-id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "could not parse product id "+err.Error())
+	}
+
+	whereUsed, err := ph.service.GetProductWhereUsed(c.Request().Context(), productId)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "could not get where used "+err.Error())
+	}
+	return render(c, http.StatusOK, components.Index(components.WhereUsed(*whereUsed)))
+}
@@ -39,3 +39,12 @@ func AppendAndGetPtr[T any](slice *[]T, value T) *T {
 func PtrsEqual[T comparable](a, b *T) bool {
 	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
 }
+
+// Deref returns the value a points to, or the zero value if a is nil.
+func Deref[T any](a *T) T {
+	if a == nil {
+		var zero T
+		return zero
+	}
+	return *a
+}
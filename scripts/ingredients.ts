@@ -60,8 +60,11 @@ export function getIngredientsData(): IngredientsData {
             const unit = this.units[ingredientPrice.unit_id];
             if (!unit) return;
             const parsed = parseFloat(ingredient.displayPrice);
-            if (!Number.isNaN(parsed)) {
-                ingredientPrice.price = (parsed / ingredientPrice.quantity) * unit.factor;
+            const deposit = parseFloat(ingredient.displayDeposit || '0');
+            if (!Number.isNaN(parsed) && !Number.isNaN(deposit)) {
+                // the entered pack price includes the deposit, the stored price does not
+                ingredientPrice.price = ((parsed - deposit) / ingredientPrice.quantity) * unit.factor;
+                ingredientPrice.deposit = deposit;
                 console.log(ingredientPrice.price);
             }
         },
@@ -75,13 +78,18 @@ export function getIngredientsData(): IngredientsData {
             const isBase = ingredient.price.base_product_id === null;
             const ingredientPrice = ingredient.price;
             const unit = this.units[ingredientPrice.unit_id];
-            if (!unit) return ingredient as IngredientExtended;
-            const displayPrice = ((ingredientPrice.price / unit.factor) * ingredientPrice.quantity).toFixed(2);
+            if (!unit) return { ...ingredient, allergens: ingredient.allergens ?? [] } as IngredientExtended;
+            const displayPrice = (
+                (ingredientPrice.price / unit.factor) * ingredientPrice.quantity + ingredientPrice.deposit
+            ).toFixed(2);
             return {
                 ...ingredient,
                 isBase: isBase,
                 editing: false,
                 displayPrice: displayPrice,
+                displayDeposit: ingredientPrice.deposit.toFixed(2),
+                displayAbv: ingredient.abv === null ? '' : ingredient.abv.toString(),
+                allergens: ingredient.allergens ?? [],
                 displayQuantity: ingredientPrice.quantity.toFixed(2),
                 unit: unit,
             };
@@ -9,6 +9,10 @@ import {
 import { Unit } from './types/common';
 import { createEditingHelpers } from './utils';
 
+// the base units of liter and kilogram, spirits are taxed by volume like
+// isVolumeUnit does on the server
+const volumeBaseUnitIds = [1, 10];
+
 export function getProductEditData(): ProductEditData {
     const vmText = document.getElementById('viewModel')!.textContent!;
     const parsedVm: ProductEditViewModel = JSON.parse(vmText) as ProductEditViewModel;
@@ -84,16 +88,64 @@ export function createProductEditModel(vm: ProductEditViewModel): ProductEditDat
             return (ingredient.prices[0].price * this.newIngredientAmount / unit.factor).toFixed(2);
         },
 
+        usageDuty(usage: IngredientUsage): number {
+            const ingredient = this.ingredients[usage.ingredient_id];
+            const unit = this.units[usage.unit_id];
+            if (!ingredient?.prices || ingredient.prices.length === 0 || !unit) return 0;
+            // base products already include their duty in their price
+            if (ingredient.prices[0].base_product_id !== null) return 0;
+            const { abv, duty_excluded } = ingredient.ingredient;
+            if (abv === null || !duty_excluded || !volumeBaseUnitIds.includes(unit.base_unit_id ?? unit.id)) {
+                return 0;
+            }
+            return usage.quantity * abv / 100 * this.cost_settings.spirits_duty_rate;
+        },
+
         get productCost(): string {
             console.log(this.ingredient_usages_ext);
             console.log(this.ingredients);
+            // the spirits duty of the direct usages is added like on the server
             return this.ingredient_usages_ext.reduce((cost, usage) => {
                 const ingredient = this.ingredients[usage.ingredient_id];
                 if (!ingredient?.prices || ingredient.prices.length === 0) return cost;
-                return cost + ingredient.prices[0].price * usage.quantity;
+                return cost + ingredient.prices[0].price * usage.quantity + this.usageDuty(usage);
+            }, 0).toFixed(2);
+        },
+
+        get laborCost(): string {
+            const minutes = Number(this.product.product.prep_minutes);
+            if (Number.isNaN(minutes)) return '0.00';
+            return (minutes / 60 * this.cost_settings.labor_rate).toFixed(2);
+        },
+
+        get overheadCost(): string {
+            const categoryId = this.categories[this.selectedCat]?.id;
+            const ingredientCost = parseFloat(this.productCost);
+            return (this.cost_settings.overhead_rules ?? []).reduce((cost, rule) => {
+                if (rule.category_id !== null && rule.category_id !== categoryId) return cost;
+                if (rule.kind === 'percent') return cost + ingredientCost * rule.amount / 100;
+                return cost + rule.amount;
             }, 0).toFixed(2);
         },
 
+        get fullCost(): string {
+            return (
+                parseFloat(this.productCost) + parseFloat(this.laborCost) + parseFloat(this.overheadCost)
+            ).toFixed(2);
+        },
+
+        // the part of the cost the multiplicator is applied to
+        get basisCost(): string {
+            switch (this.cost_settings.cost_basis) {
+                case 'prime':
+                    return (parseFloat(this.productCost) + parseFloat(this.laborCost)).toFixed(2);
+                case 'full':
+                    return this.fullCost;
+                default:
+                    return this.productCost;
+            }
+        },
+
         modifyIngredientUsage(
             usage: IngredientUsage,
         ): IngredientUsageExtended {
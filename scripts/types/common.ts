@@ -1,6 +1,8 @@
 export interface Ingredient {
     id: number;
     name: string;
+    abv: number | null;
+    duty_excluded: boolean;
 }
 
 export interface IngredientPrice {
@@ -11,6 +13,7 @@ export interface IngredientPrice {
     unit_id: number;
     ingredient_id: number;
     base_product_id: number | null;
+    deposit: number;
 }
 
 export interface IngredientWithPrices {
@@ -18,6 +21,12 @@ export interface IngredientWithPrices {
     prices: IngredientPrice[];
 }
 
+export interface Allergen {
+    id: number;
+    code: string;
+    name: string;
+}
+
 export interface Unit {
     id: number;
     name: string;
@@ -1,6 +1,7 @@
-import { Unit, EditableWithId, Ingredient, IngredientPrice } from './common';
+import { Unit, EditableWithId, Ingredient, IngredientPrice, Allergen } from './common';
 
 export interface IngredientWithPrice extends Ingredient {
+    allergens: number[] | null;
     price: IngredientPrice;
 }
 
@@ -8,11 +9,15 @@ export interface IngredientsViewModel {
     product_names: Record<number, string>;
     ingredients: IngredientWithPrice[];
     units: Record<number, Unit>
+    allergens: Allergen[];
 }
 
 export interface IngredientExtended extends IngredientWithPrice, EditableWithId {
+    allergens: number[];
     isBase: boolean;
     displayPrice: string;
+    displayDeposit: string;
+    displayAbv: string;
     displayQuantity: string;
     unit: Unit;
 }
@@ -1,4 +1,4 @@
-import { IngredientWithPrices, Unit } from './common';
+import { Allergen, IngredientWithPrices, Unit } from './common';
 
 export interface Product {
     id: number;
@@ -6,17 +6,59 @@ export interface Product {
     price: number;
     multiplicator: number;
     category_id: number;
+    prep_minutes: number;
+    deposit: number;
+    servings: number;
+}
+
+export interface CostBreakdown {
+    ingredients: number;
+    labor: number;
+    overhead: number;
+    full: number;
+    basis: number;
 }
 
 export interface ProductWithCost {
     product: Product;
     cost: number;
+    breakdown: CostBreakdown;
+    allergens: Allergen[];
+}
+
+export interface OverheadRule {
+    id: number;
+    name: string;
+    kind: 'fixed' | 'percent';
+    amount: number;
+    category_id: number | null;
+}
+
+export interface CostSettings {
+    labor_rate: number;
+    cost_basis: 'ingredients' | 'prime' | 'full';
+    stock_valuation: 'latest' | 'average' | 'fifo';
+    spirits_duty_rate: number;
+    standard_drink_grams: number;
+    overhead_rules: OverheadRule[];
+}
+
+export interface AlcoholInfo {
+    volume: number;
+    pure_alcohol: number;
+    abv: number;
+    standard_drinks: number;
+    duty: number;
 }
 
 export interface Category {
     id: number;
     name: string;
     vat: number;
+    dine_in_vat_rate_id: number | null;
+    takeaway_vat_rate_id: number | null;
+    dine_in_vat: number;
+    takeaway_vat: number;
 }
 
 export interface IngredientUsage {
@@ -40,6 +82,8 @@ export interface ProductEditViewModel {
     ingredient_usages: IngredientUsage[];
     ingredients: Record<number, IngredientWithPrices>;
     units: Record<number, Unit>
+    cost_settings: CostSettings;
+    alcohol: AlcoholInfo;
 }
 
 export type ProductEditData = ProductEditViewModel & {
@@ -51,8 +95,13 @@ export type ProductEditData = ProductEditViewModel & {
     usageBackup: Record<number, IngredientUsageExtended>;
     getFilteredUnitsForUnitId: (unitId: number) => Unit[];
     getSafeUnitIdFromIngredient: (ingredientId: number) => number | null;
+    usageDuty: (usage: IngredientUsage) => number;
     readonly newIngredientCost: string;
     readonly productCost: string;
+    readonly laborCost: string;
+    readonly overheadCost: string;
+    readonly fullCost: string;
+    readonly basisCost: string;
     startEditing: (usage: IngredientUsageExtended) => void;
     cancelEditing: (usage: IngredientUsageExtended) => void;
     removeItem: (usageId: number) => void;
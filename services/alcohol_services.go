@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/mike-jl/price_calc/db"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+// ids of the base units seeded by the first migration. Quantities in liters or
+// kilograms add to the volume of a drink, kilograms count like liters of water.
+const (
+	unitLiter    int64 = 1
+	unitKilogram int64 = 10
+)
+
+const (
+	settingSpiritsDutyRate    = "spirits_duty_rate"
+	settingStandardDrinkGrams = "standard_drink_grams"
+)
+
+// ethanolDensity in g/ml
+const ethanolDensity = 0.789
+
+func validateAbv(abv *float64) error {
+	if abv != nil && (*abv < 0 || *abv > 100) {
+		return errors.New("abv must be between 0 and 100")
+	}
+	return nil
+}
+
+func isVolumeUnit(baseUnitId int64) bool {
+	return baseUnitId == unitLiter || baseUnitId == unitKilogram
+}
+
+func settingFloat(ctx context.Context, qtx *db.Queries, key string) (float64, error) {
+	value, err := qtx.GetSetting(ctx, key)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// alcoholUsage is one line of a recipe as far as alcohol is concerned. Usages of
+// base products carry the alcohol info of one unit of that product.
+type alcoholUsage struct {
+	quantity     float64
+	volume       bool
+	abv          *float64
+	dutyExcluded bool
+	baseProduct  *viewmodels.AlcoholInfo
+}
+
+func summarizeAlcohol(
+	usages []alcoholUsage,
+	dutyRate, standardDrinkGrams float64,
+) viewmodels.AlcoholInfo {
+	info := viewmodels.AlcoholInfo{}
+	for _, usage := range usages {
+		if usage.baseProduct != nil {
+			info.Volume += usage.quantity * usage.baseProduct.Volume
+			info.PureAlcohol += usage.quantity * usage.baseProduct.PureAlcohol
+			info.Duty += usage.quantity * usage.baseProduct.Duty
+			continue
+		}
+		if usage.abv == nil || !usage.volume {
+			continue
+		}
+		alcohol := usage.quantity * *usage.abv / 100
+		info.Volume += usage.quantity
+		info.PureAlcohol += alcohol
+		if usage.dutyExcluded {
+			info.Duty += alcohol * dutyRate
+		}
+	}
+
+	if info.Volume > 0 {
+		info.Abv = info.PureAlcohol / info.Volume * 100
+	}
+	if standardDrinkGrams > 0 {
+		info.StandardDrinks = info.PureAlcohol * 1000 * ethanolDensity / standardDrinkGrams
+	}
+	return info
+}
+
+// ingredientDuty is the spirits duty that has to be added to the cost of a usage.
+func ingredientDuty(usage db.GetIngredientUsageForProductWithPriceRow, dutyRate float64) float64 {
+	if usage.Abv == nil || usage.DutyExcluded == nil || !*usage.DutyExcluded ||
+		!isVolumeUnit(usage.BaseUnitID) {
+		return 0
+	}
+	return usage.Quantity * *usage.Abv / 100 * dutyRate
+}
+
+func (pc *PriceCalcService) GetProductAlcohol(
+	ctx context.Context,
+	productId int64,
+) (*viewmodels.AlcoholInfo, error) {
+	dutyRate, err := settingFloat(ctx, pc.queries, settingSpiritsDutyRate)
+	if err != nil {
+		return nil, err
+	}
+	standardDrinkGrams, err := settingFloat(ctx, pc.queries, settingStandardDrinkGrams)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := pc.calculateProductAlcohol(
+		ctx,
+		productId,
+		map[int64]bool{},
+		dutyRate,
+		standardDrinkGrams,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (pc *PriceCalcService) calculateProductAlcohol(
+	ctx context.Context,
+	productId int64,
+	visited map[int64]bool,
+	dutyRate, standardDrinkGrams float64,
+) (viewmodels.AlcoholInfo, error) {
+	if visited[productId] {
+		return viewmodels.AlcoholInfo{}, fmt.Errorf("circular dependency detected on product %d", productId)
+	}
+	visited[productId] = true
+	defer delete(visited, productId)
+
+	rows, err := pc.queries.GetIngredientUsageForProductWithPrice(ctx, productId)
+	if err != nil {
+		return viewmodels.AlcoholInfo{}, err
+	}
+
+	usages := make([]alcoholUsage, 0, len(rows))
+	for _, row := range rows {
+		usage := alcoholUsage{
+			quantity:     row.Quantity,
+			volume:       isVolumeUnit(row.BaseUnitID),
+			abv:          row.Abv,
+			dutyExcluded: row.DutyExcluded != nil && *row.DutyExcluded,
+		}
+		if row.BaseProductID != nil {
+			sub, err := pc.calculateProductAlcohol(
+				ctx,
+				*row.BaseProductID,
+				visited,
+				dutyRate,
+				standardDrinkGrams,
+			)
+			if err != nil {
+				return viewmodels.AlcoholInfo{}, err
+			}
+			usage.baseProduct = &sub
+		}
+		usages = append(usages, usage)
+	}
+
+	components, err := pc.queries.GetBundleComponents(ctx, productId)
+	if err != nil {
+		return viewmodels.AlcoholInfo{}, err
+	}
+	for _, component := range components {
+		sub, err := pc.calculateProductAlcohol(
+			ctx,
+			component.ProductID,
+			visited,
+			dutyRate,
+			standardDrinkGrams,
+		)
+		if err != nil {
+			return viewmodels.AlcoholInfo{}, err
+		}
+		usages = append(usages, alcoholUsage{quantity: component.Quantity, baseProduct: &sub})
+	}
+
+	return summarizeAlcohol(usages, dutyRate, standardDrinkGrams), nil
+}
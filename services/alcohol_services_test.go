@@ -0,0 +1,86 @@
+package services
+
+import (
+	"testing"
+
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeAlcohol(t *testing.T) {
+	abv := func(v float64) *float64 { return &v }
+	ginAndTonic := []alcoholUsage{
+		{quantity: 0.04, volume: true, abv: abv(40), dutyExcluded: true},
+		{quantity: 0.1, volume: true, abv: abv(0)},
+		// ice in kg
+		{quantity: 0.05, volume: true, abv: abv(0)},
+		// lime garnish in pieces
+		{quantity: 1, volume: false},
+	}
+
+	tests := []struct {
+		name     string
+		usages   []alcoholUsage
+		expected viewmodels.AlcoholInfo
+	}{
+		{
+			name:   "Diluted spirit with duty",
+			usages: ginAndTonic,
+			expected: viewmodels.AlcoholInfo{
+				Volume:         0.19,
+				PureAlcohol:    0.016,
+				Abv:            8.421,
+				StandardDrinks: 1.262,
+				Duty:           0.208,
+			},
+		},
+		{
+			name: "Duty already included in the price",
+			usages: []alcoholUsage{
+				{quantity: 0.02, volume: true, abv: abv(40)},
+			},
+			expected: viewmodels.AlcoholInfo{
+				Volume:         0.02,
+				PureAlcohol:    0.008,
+				Abv:            40,
+				StandardDrinks: 0.631,
+			},
+		},
+		{
+			name: "Base product",
+			usages: []alcoholUsage{
+				{quantity: 2, baseProduct: &viewmodels.AlcoholInfo{
+					Volume:      0.19,
+					PureAlcohol: 0.016,
+					Duty:        0.2085,
+				}},
+			},
+			expected: viewmodels.AlcoholInfo{
+				Volume:         0.38,
+				PureAlcohol:    0.032,
+				Abv:            8.421,
+				StandardDrinks: 2.525,
+				Duty:           0.417,
+			},
+		},
+		{
+			name: "Without alcohol",
+			usages: []alcoholUsage{
+				{quantity: 0.3, volume: true, abv: abv(0)},
+				{quantity: 0.2, volume: false},
+			},
+			expected: viewmodels.AlcoholInfo{Volume: 0.3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := summarizeAlcohol(tt.usages, 13.03, 10)
+			assert.InDelta(t, tt.expected.Volume, info.Volume, 0.001)
+			assert.InDelta(t, tt.expected.PureAlcohol, info.PureAlcohol, 0.001)
+			assert.InDelta(t, tt.expected.Abv, info.Abv, 0.001)
+			assert.InDelta(t, tt.expected.StandardDrinks, info.StandardDrinks, 0.001)
+			assert.InDelta(t, tt.expected.Duty, info.Duty, 0.001)
+		})
+	}
+}
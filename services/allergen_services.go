@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/mike-jl/price_calc/db"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+// allergenGraph is the recipe graph of all products, the same one
+// calculateProductCost walks: ingredient usages, base products used as
+// ingredients and bundle components.
+type allergenGraph struct {
+	ingredientAllergens map[int64][]int64
+	usages              map[int64][]db.GetIngredientUsageGraphRow
+	components          map[int64][]int64
+}
+
+// productAllergens returns the sorted allergen ids of a product. Results are
+// stored in memo, products already on the path are skipped.
+func (g allergenGraph) productAllergens(
+	productId int64,
+	memo map[int64][]int64,
+	visited map[int64]bool,
+) []int64 {
+	if allergens, ok := memo[productId]; ok {
+		return allergens
+	}
+	if visited[productId] {
+		return nil
+	}
+	visited[productId] = true
+	defer delete(visited, productId)
+
+	allergens := []int64{}
+	for _, usage := range g.usages[productId] {
+		if usage.BaseProductID != nil {
+			allergens = append(allergens, g.productAllergens(*usage.BaseProductID, memo, visited)...)
+		}
+		allergens = append(allergens, g.ingredientAllergens[usage.IngredientID]...)
+	}
+	for _, componentId := range g.components[productId] {
+		allergens = append(allergens, g.productAllergens(componentId, memo, visited)...)
+	}
+
+	slices.Sort(allergens)
+	allergens = slices.Compact(allergens)
+	memo[productId] = allergens
+	return allergens
+}
+
+func (pc *PriceCalcService) GetAllergens(ctx context.Context) ([]db.Allergen, error) {
+	return pc.queries.GetAllergens(ctx)
+}
+
+// GetIngredientAllergens returns the allergen ids per ingredient id
+func (pc *PriceCalcService) GetIngredientAllergens(
+	ctx context.Context,
+) (map[int64][]int64, error) {
+	rows, err := pc.queries.GetIngredientAllergens(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := map[int64][]int64{}
+	for _, row := range rows {
+		out[row.IngredientID] = append(out[row.IngredientID], row.AllergenID)
+	}
+	return out, nil
+}
+
+func (pc *PriceCalcService) setIngredientAllergens(
+	ctx context.Context,
+	qtx *db.Queries,
+	ingredientId int64,
+	allergenIds []int64,
+) error {
+	allergens, err := qtx.GetAllergens(ctx)
+	if err != nil {
+		return err
+	}
+	for _, allergenId := range allergenIds {
+		if !slices.ContainsFunc(allergens, func(a db.Allergen) bool { return a.ID == allergenId }) {
+			return fmt.Errorf("allergen with id %d not found", allergenId)
+		}
+	}
+
+	err = qtx.DeleteIngredientAllergens(ctx, ingredientId)
+	if err != nil {
+		return err
+	}
+	for _, allergenId := range allergenIds {
+		err = qtx.InsertIngredientAllergen(ctx, db.InsertIngredientAllergenParams{
+			IngredientID: ingredientId,
+			AllergenID:   allergenId,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (pc *PriceCalcService) getAllergenGraph(ctx context.Context) (*allergenGraph, error) {
+	ingredientAllergens, err := pc.GetIngredientAllergens(ctx)
+	if err != nil {
+		return nil, err
+	}
+	usages, err := pc.queries.GetIngredientUsageGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+	components, err := pc.queries.GetBundleComponentGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := allergenGraph{
+		ingredientAllergens: ingredientAllergens,
+		usages:              map[int64][]db.GetIngredientUsageGraphRow{},
+		components:          map[int64][]int64{},
+	}
+	for _, usage := range usages {
+		graph.usages[usage.ProductID] = append(graph.usages[usage.ProductID], usage)
+	}
+	for _, component := range components {
+		graph.components[component.BundleID] = append(
+			graph.components[component.BundleID],
+			component.ProductID,
+		)
+	}
+	return &graph, nil
+}
+
+func (pc *PriceCalcService) addAllergens(
+	ctx context.Context,
+	products []viewmodels.ProductWithCost,
+) error {
+	allergens, err := pc.queries.GetAllergens(ctx)
+	if err != nil {
+		return err
+	}
+	allergensById := make(map[int64]db.Allergen, len(allergens))
+	for _, allergen := range allergens {
+		allergensById[allergen.ID] = allergen
+	}
+
+	graph, err := pc.getAllergenGraph(ctx)
+	if err != nil {
+		return err
+	}
+
+	memo := map[int64][]int64{}
+	for i, product := range products {
+		products[i].Allergens = []db.Allergen{}
+		for _, allergenId := range graph.productAllergens(product.Product.ID, memo, map[int64]bool{}) {
+			products[i].Allergens = append(products[i].Allergens, allergensById[allergenId])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/mike-jl/price_calc/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProductAllergens(t *testing.T) {
+	const (
+		gluten int64 = 1
+		eggs   int64 = 3
+		milk   int64 = 7
+		celery int64 = 9
+	)
+	burgerSauce := int64(10)
+	graph := allergenGraph{
+		ingredientAllergens: map[int64][]int64{
+			1: {gluten},       // bun
+			2: {eggs, milk},   // mayo
+			3: {celery},       // ketchup
+			4: {milk},         // cheese
+			5: {gluten, milk}, // ingredient made from the burger sauce product
+		},
+		usages: map[int64][]db.GetIngredientUsageGraphRow{
+			// burger sauce
+			burgerSauce: {
+				{ProductID: burgerSauce, IngredientID: 2},
+				{ProductID: burgerSauce, IngredientID: 3},
+			},
+			// burger
+			11: {
+				{ProductID: 11, IngredientID: 1},
+				{ProductID: 11, IngredientID: 4},
+				{ProductID: 11, IngredientID: 6, BaseProductID: &burgerSauce},
+			},
+			// fries
+			12: {
+				{ProductID: 12, IngredientID: 7},
+			},
+		},
+		components: map[int64][]int64{
+			// menu
+			13: {11, 12},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		productId int64
+		expected  []int64
+	}{
+		{name: "Ingredients only", productId: burgerSauce, expected: []int64{eggs, milk, celery}},
+		{name: "Base product", productId: 11, expected: []int64{gluten, eggs, milk, celery}},
+		{name: "No allergens", productId: 12, expected: []int64{}},
+		{name: "Bundle", productId: 13, expected: []int64{gluten, eggs, milk, celery}},
+		{name: "Unknown product", productId: 99, expected: []int64{}},
+	}
+
+	memo := map[int64][]int64{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allergens := graph.productAllergens(tt.productId, memo, map[int64]bool{})
+			assert.Equal(t, tt.expected, allergens)
+		})
+	}
+}
+
+func TestProductAllergensCircular(t *testing.T) {
+	a, b := int64(1), int64(2)
+	graph := allergenGraph{
+		ingredientAllergens: map[int64][]int64{1: {1}, 2: {2}},
+		usages: map[int64][]db.GetIngredientUsageGraphRow{
+			a: {{ProductID: a, IngredientID: 1, BaseProductID: &b}},
+			b: {{ProductID: b, IngredientID: 2, BaseProductID: &a}},
+		},
+	}
+
+	allergens := graph.productAllergens(a, map[int64][]int64{}, map[int64]bool{})
+	assert.Equal(t, []int64{1, 2}, allergens)
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mike-jl/price_calc/db"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+func (pc *PriceCalcService) bundleComponentCost(
+	ctx context.Context,
+	qtx *db.Queries,
+	component db.GetBundleComponentsRow,
+) (float64, error) {
+	if component.Cost != nil {
+		return *component.Cost, nil
+	}
+	// not supposed to happen, but if the component has no cached cost yet,
+	// calculate it without writing the cache, this is also used for reading
+	return pc.calculateProductCost(component.ProductID, map[int64]bool{}, ctx, qtx)
+}
+
+func (pc *PriceCalcService) updateBundleCosts(
+	ctx context.Context,
+	qtx *db.Queries,
+	productID int64,
+) error {
+	bundles, err := qtx.GetBundlesFromProduct(ctx, productID)
+	if err != nil {
+		return err
+	}
+	for _, bundle := range bundles {
+		_, err = pc.UpdateProductCost(ctx, qtx, bundle.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckBundleCircularDependency reports whether adding productId as a component
+// of bundleId would make the bundle contain itself.
+func (pc *PriceCalcService) CheckBundleCircularDependency(
+	ctx context.Context,
+	bundleId, productId int64,
+) (bool, error) {
+	return pc.checkBundleCircular(ctx, bundleId, productId, make(map[int64]bool))
+}
+
+func (pc *PriceCalcService) checkBundleCircular(
+	ctx context.Context,
+	targetBundleId, currentProductId int64,
+	visited map[int64]bool,
+) (bool, error) {
+	if currentProductId == targetBundleId {
+		return true, nil
+	}
+	if visited[currentProductId] {
+		return false, nil // already checked
+	}
+	visited[currentProductId] = true
+
+	componentIds, err := pc.queries.GetBundleComponentProductIds(ctx, currentProductId)
+	if err != nil {
+		return false, err
+	}
+	for _, componentId := range componentIds {
+		circ, err := pc.checkBundleCircular(ctx, targetBundleId, componentId, visited)
+		if err != nil || circ {
+			return circ, err
+		}
+	}
+	return false, nil
+}
+
+func (pc *PriceCalcService) GetBundle(
+	ctx context.Context,
+	bundleId int64,
+) (*viewmodels.Bundle, error) {
+	product, err := pc.GetProductWithCost(bundleId)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := pc.queries.GetBundleComponents(ctx, bundleId)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := pc.GetCategories()
+	if err != nil {
+		return nil, err
+	}
+	resolver, err := pc.GetVatResolver(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	categoriesMap := make(map[int64]db.Category, len(categories))
+	for _, category := range categories {
+		categoriesMap[category.ID] = category
+	}
+
+	now := time.Now()
+	components := make([]viewmodels.BundleComponent, len(rows))
+	for i, row := range rows {
+		cost, err := pc.bundleComponentCost(ctx, pc.queries, row)
+		if err != nil {
+			return nil, err
+		}
+		components[i] = viewmodels.BundleComponent{
+			ID:        row.ID,
+			ProductID: row.ProductID,
+			Name:      row.Name,
+			Quantity:  row.Quantity,
+			Price:     row.Price,
+			Cost:      cost,
+			Vat:       resolver.CategoryVat(categoriesMap[row.CategoryID], VatChannelDineIn, now),
+		}
+	}
+
+	bundle := summarizeBundle(product.Product, components)
+	return &bundle, nil
+}
+
+// summarizeBundle sums up the components of a bundle. The blended VAT splits the
+// bundle price in proportion to the individual prices of its components, so a
+// drink at 19% and a burger at 7% are taxed according to their share.
+func summarizeBundle(
+	product db.Product,
+	components []viewmodels.BundleComponent,
+) viewmodels.Bundle {
+	bundle := viewmodels.Bundle{
+		Product:    product,
+		Components: components,
+	}
+
+	weightedVat := 0.0
+	for _, component := range components {
+		price := component.Price * component.Quantity
+		bundle.Cost += component.Cost * component.Quantity
+		bundle.IndividualPrice += price
+		weightedVat += price * component.Vat
+	}
+
+	if bundle.IndividualPrice > 0 {
+		bundle.BlendedVat = weightedVat / bundle.IndividualPrice
+		bundle.Discount = bundle.IndividualPrice - product.Price
+		bundle.DiscountPercent = bundle.Discount / bundle.IndividualPrice * 100
+	}
+
+	bundle.NetPrice = product.Price / (1 + bundle.BlendedVat/100)
+	bundle.Margin = bundle.NetPrice - bundle.Cost
+
+	return bundle
+}
+
+func (pc *PriceCalcService) PutBundleComponent(
+	ctx context.Context,
+	bundleId, productId int64,
+	quantity float64,
+) (*db.BundleComponent, error) {
+	if quantity <= 0 {
+		return nil, errors.New("quantity must be greater than 0")
+	}
+
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	component, err := qtx.PutBundleComponent(ctx, db.PutBundleComponentParams{
+		BundleID:  bundleId,
+		ProductID: productId,
+		Quantity:  quantity,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = pc.UpdateProductCost(ctx, qtx, bundleId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &component, nil
+}
+
+func (pc *PriceCalcService) UpdateBundleComponent(
+	ctx context.Context,
+	bundleComponentId int64,
+	quantity float64,
+) (*db.BundleComponent, error) {
+	if quantity <= 0 {
+		return nil, errors.New("quantity must be greater than 0")
+	}
+
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	component, err := qtx.UpdateBundleComponent(ctx, db.UpdateBundleComponentParams{
+		ID:       bundleComponentId,
+		Quantity: quantity,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = pc.UpdateProductCost(ctx, qtx, component.BundleID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &component, nil
+}
+
+// DeleteBundleComponent removes a component and returns the id of the bundle it belonged to.
+func (pc *PriceCalcService) DeleteBundleComponent(
+	ctx context.Context,
+	bundleComponentId int64,
+) (int64, error) {
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	bundleId, err := qtx.DeleteBundleComponent(ctx, bundleComponentId)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = pc.UpdateProductCost(ctx, qtx, bundleId)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return bundleId, nil
+}
+
+func (pc *PriceCalcService) GetBundlesWithProduct(
+	ctx context.Context,
+	productId int64,
+) ([]string, error) {
+	bundles, err := pc.queries.GetBundlesFromProduct(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+	bundleNames := make([]string, len(bundles))
+	for i, bundle := range bundles {
+		bundleNames[i] = bundle.Name
+	}
+	return bundleNames, nil
+}
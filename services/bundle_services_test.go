@@ -0,0 +1,71 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/mike-jl/price_calc/db"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeBundle(t *testing.T) {
+	tests := []struct {
+		name               string
+		product            db.Product
+		components         []viewmodels.BundleComponent
+		expectedCost       float64
+		expectedIndividual float64
+		expectedDiscount   float64
+		expectedBlendedVat float64
+	}{
+		{
+			name:    "Menu with mixed VAT rates",
+			product: db.Product{ID: 1, Name: "Menu", Price: 10},
+			components: []viewmodels.BundleComponent{
+				{ProductID: 2, Name: "Burger", Quantity: 1, Price: 8, Cost: 2.5, Vat: 7},
+				{ProductID: 3, Name: "Fries", Quantity: 1, Price: 2, Cost: 0.5, Vat: 7},
+				{ProductID: 4, Name: "Cola", Quantity: 1, Price: 2, Cost: 0.4, Vat: 19},
+			},
+			expectedCost:       3.4,
+			expectedIndividual: 12,
+			expectedDiscount:   2,
+			expectedBlendedVat: 9,
+		},
+		{
+			name:    "Quantities are applied to cost and price",
+			product: db.Product{ID: 1, Name: "Two Beers", Price: 7},
+			components: []viewmodels.BundleComponent{
+				{ProductID: 2, Name: "Beer", Quantity: 2, Price: 4, Cost: 1, Vat: 19},
+			},
+			expectedCost:       2,
+			expectedIndividual: 8,
+			expectedDiscount:   1,
+			expectedBlendedVat: 19,
+		},
+		{
+			name:               "Empty bundle",
+			product:            db.Product{ID: 1, Name: "Empty", Price: 5},
+			components:         []viewmodels.BundleComponent{},
+			expectedCost:       0,
+			expectedIndividual: 0,
+			expectedDiscount:   0,
+			expectedBlendedVat: 0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			bundle := summarizeBundle(tc.product, tc.components)
+			assert.InDelta(t, tc.expectedCost, bundle.Cost, 0.0001)
+			assert.InDelta(t, tc.expectedIndividual, bundle.IndividualPrice, 0.0001)
+			assert.InDelta(t, tc.expectedDiscount, bundle.Discount, 0.0001)
+			assert.InDelta(t, tc.expectedBlendedVat, bundle.BlendedVat, 0.0001)
+			assert.InDelta(
+				t,
+				tc.product.Price/(1+tc.expectedBlendedVat/100)-tc.expectedCost,
+				bundle.Margin,
+				0.0001,
+			)
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mike-jl/price_calc/db"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+type UpdateSalesChannelParams struct {
+	ID                int64
+	Name              string
+	VatChannel        VatChannel
+	CommissionPercent float64
+	FixedFee          float64
+	PaymentFeePercent float64
+}
+
+func (p UpdateSalesChannelParams) validate() error {
+	if p.VatChannel != VatChannelDineIn && p.VatChannel != VatChannelTakeaway {
+		return fmt.Errorf("invalid vat channel %q", p.VatChannel)
+	}
+	if p.CommissionPercent < 0 || p.FixedFee < 0 || p.PaymentFeePercent < 0 {
+		return errors.New("fees must not be negative")
+	}
+	return nil
+}
+
+func (pc *PriceCalcService) GetSalesChannels(ctx context.Context) ([]db.SalesChannel, error) {
+	return pc.queries.GetSalesChannels(ctx)
+}
+
+func (pc *PriceCalcService) PutSalesChannel(
+	ctx context.Context,
+	params UpdateSalesChannelParams,
+) (*db.SalesChannel, error) {
+	err := params.validate()
+	if err != nil {
+		return nil, err
+	}
+	channel, err := pc.queries.PutSalesChannel(ctx, db.PutSalesChannelParams{
+		Name:              params.Name,
+		VatChannel:        string(params.VatChannel),
+		CommissionPercent: params.CommissionPercent,
+		FixedFee:          params.FixedFee,
+		PaymentFeePercent: params.PaymentFeePercent,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &channel, nil
+}
+
+func (pc *PriceCalcService) UpdateSalesChannel(
+	ctx context.Context,
+	params UpdateSalesChannelParams,
+) (*db.SalesChannel, error) {
+	err := params.validate()
+	if err != nil {
+		return nil, err
+	}
+	channel, err := pc.queries.UpdateSalesChannel(ctx, db.UpdateSalesChannelParams{
+		ID:                params.ID,
+		Name:              params.Name,
+		VatChannel:        string(params.VatChannel),
+		CommissionPercent: params.CommissionPercent,
+		FixedFee:          params.FixedFee,
+		PaymentFeePercent: params.PaymentFeePercent,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &channel, nil
+}
+
+func (pc *PriceCalcService) DeleteSalesChannel(ctx context.Context, id int64) error {
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	err = qtx.DeleteChannelPrices(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = qtx.DeleteChannelPackagingForChannel(ctx, id)
+	if err != nil {
+		return err
+	}
+	num, err := qtx.DeleteSalesChannel(ctx, id)
+	if err != nil {
+		return err
+	}
+	if num < 1 {
+		return ErrNoRowsAffected
+	}
+
+	return tx.Commit()
+}
+
+// SetProductChannelPrice overrides the price of a product in a channel.
+// A nil price removes the override, so the product price is used again.
+func (pc *PriceCalcService) SetProductChannelPrice(
+	ctx context.Context,
+	productId, channelId int64,
+	price *float64,
+) error {
+	// foreign keys are not enforced, so make sure both sides exist
+	_, err := pc.queries.GetProductWithCost(ctx, productId)
+	if err != nil {
+		return err
+	}
+	_, err = pc.queries.GetSalesChannel(ctx, channelId)
+	if err != nil {
+		return err
+	}
+
+	if price == nil {
+		return pc.queries.DeleteProductChannelPrice(ctx, db.DeleteProductChannelPriceParams{
+			ProductID: productId,
+			ChannelID: channelId,
+		})
+	}
+	return pc.queries.PutProductChannelPrice(ctx, db.PutProductChannelPriceParams{
+		ProductID: productId,
+		ChannelID: channelId,
+		Price:     *price,
+	})
+}
+
+// calculateChannelMargin works out what is left of a product's price in a channel.
+// Commission and payment fees are charged on the gross price the guest pays.
+func calculateChannelMargin(
+	product viewmodels.ProductWithCost,
+	channel db.SalesChannel,
+	vat float64,
+	overridePrice *float64,
+	packaging []viewmodels.PackagingItem,
+) viewmodels.ChannelMargin {
+	margin := viewmodels.ChannelMargin{
+		ChannelID:   channel.ID,
+		ChannelName: channel.Name,
+		Price:       product.Product.Price,
+		Vat:         vat,
+		Cost:        product.Breakdown.Full,
+	}
+	if overridePrice != nil {
+		margin.Price = *overridePrice
+		margin.PriceOverridden = true
+	}
+
+	margin.NetPrice = margin.Price / (1 + vat/100)
+	margin.Fees = margin.Price*(channel.CommissionPercent+channel.PaymentFeePercent)/100 +
+		channel.FixedFee
+	margin.PackagingItems = packaging
+	for _, item := range packaging {
+		margin.Packaging += item.Cost
+	}
+	margin.Margin = margin.NetPrice - margin.Fees - margin.Cost - margin.Packaging
+	if margin.NetPrice != 0 {
+		margin.MarginPercent = margin.Margin / margin.NetPrice * 100
+	}
+
+	return margin
+}
+
+type productChannelKey struct {
+	productId int64
+	channelId int64
+}
+
+// GetChannelMargins returns the margins of every given product in every sales channel,
+// keyed by product id.
+func (pc *PriceCalcService) GetChannelMargins(
+	ctx context.Context,
+	products []viewmodels.ProductWithCost,
+) (map[int64][]viewmodels.ChannelMargin, error) {
+	channels, err := pc.queries.GetSalesChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := pc.GetCategories()
+	if err != nil {
+		return nil, err
+	}
+	resolver, err := pc.GetVatResolver(ctx)
+	if err != nil {
+		return nil, err
+	}
+	channelPrices, err := pc.queries.GetProductChannelPrices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	packaging, err := pc.getChannelPackaging(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	categoriesMap := make(map[int64]db.Category, len(categories))
+	for _, category := range categories {
+		categoriesMap[category.ID] = category
+	}
+	overrides := make(map[productChannelKey]float64, len(channelPrices))
+	for _, channelPrice := range channelPrices {
+		overrides[productChannelKey{channelPrice.ProductID, channelPrice.ChannelID}] = channelPrice.Price
+	}
+
+	now := time.Now()
+	out := make(map[int64][]viewmodels.ChannelMargin, len(products))
+	for _, product := range products {
+		category := categoriesMap[product.Product.CategoryID]
+		margins := make([]viewmodels.ChannelMargin, len(channels))
+		for i, channel := range channels {
+			vat := resolver.CategoryVat(category, VatChannel(channel.VatChannel), now)
+			var overridePrice *float64
+			key := productChannelKey{product.Product.ID, channel.ID}
+			if price, ok := overrides[key]; ok {
+				overridePrice = &price
+			}
+			margins[i] = calculateChannelMargin(product, channel, vat, overridePrice, packaging[key])
+		}
+		out[product.Product.ID] = margins
+	}
+
+	return out, nil
+}
+
+func (pc *PriceCalcService) GetProductChannelMargins(
+	ctx context.Context,
+	productId int64,
+) (*viewmodels.ProductWithChannelMargins, error) {
+	products, err := pc.GetProductsWithCost()
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range products {
+		if product.Product.ID != productId {
+			continue
+		}
+		margins, err := pc.GetChannelMargins(ctx, []viewmodels.ProductWithCost{product})
+		if err != nil {
+			return nil, err
+		}
+		return &viewmodels.ProductWithChannelMargins{
+			Product: product,
+			Margins: margins[productId],
+		}, nil
+	}
+	return nil, fmt.Errorf("product with id %d not found", productId)
+}
+
+func (pc *PriceCalcService) GetChannelReport(ctx context.Context) (*viewmodels.ChannelReport, error) {
+	channels, err := pc.queries.GetSalesChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	products, err := pc.GetProductsWithCost()
+	if err != nil {
+		return nil, err
+	}
+	margins, err := pc.GetChannelMargins(ctx, products)
+	if err != nil {
+		return nil, err
+	}
+
+	report := viewmodels.ChannelReport{
+		Channels: make([]viewmodels.ChannelSummary, len(channels)),
+		Products: make([]viewmodels.ProductWithChannelMargins, len(products)),
+	}
+	for i, channel := range channels {
+		report.Channels[i].Channel = channel
+	}
+	for i, product := range products {
+		report.Products[i] = viewmodels.ProductWithChannelMargins{
+			Product: product,
+			Margins: margins[product.Product.ID],
+		}
+		for j, margin := range margins[product.Product.ID] {
+			report.Channels[j].TotalMargin += margin.Margin
+			report.Channels[j].AverageMarginPercent += margin.MarginPercent
+		}
+	}
+	if len(products) > 0 {
+		for i := range report.Channels {
+			report.Channels[i].AverageMarginPercent /= float64(len(products))
+		}
+	}
+
+	return &report, nil
+}
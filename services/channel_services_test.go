@@ -0,0 +1,85 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/mike-jl/price_calc/db"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateChannelMargin(t *testing.T) {
+	product := viewmodels.ProductWithCost{
+		Product:   db.Product{ID: 1, Name: "Burger", Price: 10.7},
+		Cost:      2,
+		Breakdown: viewmodels.CostBreakdown{Ingredients: 2, Labor: 0.5, Overhead: 0.5, Full: 3},
+	}
+	overridePrice := 12.84
+
+	tests := []struct {
+		name              string
+		channel           db.SalesChannel
+		vat               float64
+		overridePrice     *float64
+		packaging         []viewmodels.PackagingItem
+		expectedPrice     float64
+		expectedNet       float64
+		expectedFees      float64
+		expectedPackaging float64
+		expectedMargin    float64
+		expectedPercent   float64
+	}{
+		{
+			name:            "Dine-in without fees",
+			channel:         db.SalesChannel{ID: 1, Name: "Dine-in"},
+			vat:             7,
+			expectedPrice:   10.7,
+			expectedNet:     10,
+			expectedFees:    0,
+			expectedMargin:  7,
+			expectedPercent: 70,
+		},
+		{
+			name: "Delivery platform with commission, payment fee and packaging",
+			channel: db.SalesChannel{
+				ID:                3,
+				Name:              "Delivery",
+				CommissionPercent: 30,
+				PaymentFeePercent: 1.5,
+				FixedFee:          0.25,
+			},
+			vat:           7,
+			overridePrice: &overridePrice,
+			packaging: []viewmodels.PackagingItem{
+				{ID: 1, Name: "Box", Quantity: 1, Cost: 0.3},
+				{ID: 2, Name: "Bag", Quantity: 1, Cost: 0.1},
+			},
+			expectedPrice:     12.84,
+			expectedNet:       12,
+			expectedFees:      4.2946,
+			expectedPackaging: 0.4,
+			expectedMargin:    4.3054,
+			expectedPercent:   35.8783,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			margin := calculateChannelMargin(
+				product,
+				tt.channel,
+				tt.vat,
+				tt.overridePrice,
+				tt.packaging,
+			)
+			assert.Equal(t, tt.channel.ID, margin.ChannelID)
+			assert.Equal(t, tt.overridePrice != nil, margin.PriceOverridden)
+			assert.InDelta(t, tt.expectedPrice, margin.Price, 0.0001)
+			assert.InDelta(t, tt.expectedNet, margin.NetPrice, 0.0001)
+			assert.InDelta(t, tt.expectedFees, margin.Fees, 0.0001)
+			assert.InDelta(t, tt.expectedPackaging, margin.Packaging, 0.0001)
+			assert.InDelta(t, tt.expectedMargin, margin.Margin, 0.0001)
+			assert.InDelta(t, tt.expectedPercent, margin.MarginPercent, 0.0001)
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mike-jl/price_calc/db"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+type CompReason string
+
+const (
+	CompReasonStaffMeal CompReason = "staff_meal"
+	CompReasonRegular   CompReason = "regular"
+	CompReasonComplaint CompReason = "complaint"
+	CompReasonPromotion CompReason = "promotion"
+	CompReasonOther     CompReason = "other"
+)
+
+// CompReasons are the reasons products can be given away for, in display
+// order
+var CompReasons = []CompReason{
+	CompReasonStaffMeal,
+	CompReasonRegular,
+	CompReasonComplaint,
+	CompReasonPromotion,
+	CompReasonOther,
+}
+
+// compMonthFormat is how months are selected and reported
+const compMonthFormat = "2006-01"
+
+// compSummaryMonths is how many months up to the selected one are summed up
+const compSummaryMonths = 12
+
+// CompParams describe products given away for free, in pieces
+type CompParams struct {
+	ProductID int64
+	Quantity  float64
+	Reason    string
+	CompedOn  time.Time
+	Staff     string
+}
+
+func validateComp(params CompParams) error {
+	if params.Quantity <= 0 {
+		return errors.New("quantity must be greater than 0")
+	}
+	if !slices.Contains(CompReasons, CompReason(params.Reason)) {
+		return fmt.Errorf("unknown reason %q", params.Reason)
+	}
+	return nil
+}
+
+// compMonths sums the comp cost of every month per reason. The rows are
+// sorted by month, reasons that are not listed are left out.
+func compMonths(rows []db.GetCompCostsByMonthRow, reasons []string) []viewmodels.CompMonth {
+	out := []viewmodels.CompMonth{}
+	for _, row := range rows {
+		i := slices.Index(reasons, row.Reason)
+		if i < 0 {
+			continue
+		}
+		if len(out) == 0 || out[len(out)-1].Month != row.Month {
+			out = append(out, viewmodels.CompMonth{
+				Month: row.Month,
+				Costs: make([]float64, len(reasons)),
+			})
+		}
+		month := &out[len(out)-1]
+		month.Costs[i] += row.Cost
+		month.Cost += row.Cost
+	}
+	return out
+}
+
+// compMonth returns the first and the last day of the month of t
+func compMonth(t time.Time) (time.Time, time.Time) {
+	first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return first, first.AddDate(0, 1, -1)
+}
+
+// LogComp values the comped products at their current cost. Their stock is
+// accounted for by the theoretical consumption like sales.
+func (pc *PriceCalcService) LogComp(ctx context.Context, params CompParams) error {
+	err := validateComp(params)
+	if err != nil {
+		return err
+	}
+
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	_, err = qtx.GetProduct(ctx, params.ProductID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("product with id %d not found", params.ProductID)
+	} else if err != nil {
+		return err
+	}
+
+	var cost float64
+	productCost, err := qtx.GetProductCost(ctx, params.ProductID)
+	if err == sql.ErrNoRows {
+		// not supposed to happen, but if the product has no cached cost yet, calculate it
+		cost, err = pc.UpdateProductCost(ctx, qtx, params.ProductID)
+		if err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	} else {
+		cost = productCost.Cost
+	}
+
+	_, err = qtx.InsertComp(ctx, db.InsertCompParams{
+		ProductID: params.ProductID,
+		Quantity:  params.Quantity,
+		Reason:    params.Reason,
+		CompedOn:  saleDay(params.CompedOn),
+		Staff:     strings.TrimSpace(params.Staff),
+		Cost:      cost * params.Quantity,
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (pc *PriceCalcService) DeleteComp(ctx context.Context, compId int64) error {
+	num, err := pc.queries.DeleteComp(ctx, compId)
+	if err != nil {
+		return err
+	}
+	if num < 1 {
+		return ErrNoRowsAffected
+	}
+	return nil
+}
+
+// GetComps reports the comps of the month of the given time and the comp
+// cost per reason of the months up to it
+func (pc *PriceCalcService) GetComps(
+	ctx context.Context,
+	month time.Time,
+) (*viewmodels.CompsViewModel, error) {
+	first, last := compMonth(month)
+	entries, err := pc.queries.GetComps(ctx, db.GetCompsParams{
+		FromDay: saleDay(first),
+		ToDay:   saleDay(last),
+	})
+	if err != nil {
+		return nil, err
+	}
+	costs, err := pc.queries.GetCompCostsByMonth(ctx, db.GetCompCostsByMonthParams{
+		FromDay: saleDay(first.AddDate(0, 1-compSummaryMonths, 0)),
+		ToDay:   saleDay(last),
+	})
+	if err != nil {
+		return nil, err
+	}
+	products, err := pc.GetProductNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := viewmodels.CompsViewModel{
+		Month:    first.Format(compMonthFormat),
+		Entries:  entries,
+		Products: products,
+		Reasons:  make([]string, len(CompReasons)),
+	}
+	for _, entry := range entries {
+		out.Cost += entry.Cost
+	}
+	for i, reason := range CompReasons {
+		out.Reasons[i] = string(reason)
+	}
+	out.Months = compMonths(costs, out.Reasons)
+	return &out, nil
+}
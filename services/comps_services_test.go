@@ -0,0 +1,50 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mike-jl/price_calc/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateComp(t *testing.T) {
+	assert.NoError(t, validateComp(CompParams{ProductID: 1, Quantity: 1, Reason: "staff_meal"}))
+	assert.NoError(t, validateComp(CompParams{ProductID: 1, Quantity: 0.5, Reason: "complaint"}))
+	assert.ErrorContains(t, validateComp(CompParams{ProductID: 1, Reason: "regular"}), "quantity")
+	assert.ErrorContains(t, validateComp(CompParams{ProductID: 1, Quantity: -1, Reason: "regular"}), "quantity")
+	assert.ErrorContains(t, validateComp(CompParams{ProductID: 1, Quantity: 1, Reason: "birthday"}), "reason")
+}
+
+func TestCompMonths(t *testing.T) {
+	reasons := []string{"staff_meal", "regular", "complaint"}
+	months := compMonths([]db.GetCompCostsByMonthRow{
+		{Month: "2026-10", Reason: "complaint", Cost: 4},
+		{Month: "2026-10", Reason: "staff_meal", Cost: 12.5},
+		{Month: "2026-09", Reason: "regular", Cost: 3},
+		{Month: "2026-09", Reason: "unknown", Cost: 100},
+		{Month: "2026-07", Reason: "staff_meal", Cost: 1},
+	}, reasons)
+
+	assert.Len(t, months, 3)
+	assert.Equal(t, "2026-10", months[0].Month)
+	assert.Equal(t, []float64{12.5, 0, 4}, months[0].Costs)
+	assert.InDelta(t, 16.5, months[0].Cost, 1e-9)
+	// reasons that are not listed are left out
+	assert.Equal(t, []float64{0, 3, 0}, months[1].Costs)
+	assert.InDelta(t, 3, months[1].Cost, 1e-9)
+	// months without comps are not listed
+	assert.Equal(t, "2026-07", months[2].Month)
+
+	assert.Empty(t, compMonths(nil, reasons))
+}
+
+func TestCompMonth(t *testing.T) {
+	first, last := compMonth(time.Date(2026, 2, 14, 18, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), first)
+	assert.Equal(t, time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC), last)
+
+	first, last = compMonth(time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), first)
+	assert.Equal(t, time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC), last)
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mike-jl/price_calc/db"
+	"github.com/mike-jl/price_calc/internal/utils"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+// sumConsumption adds the quantities of the ingredients of a recipe in base
+// units, base products and bundle components are expanded to their
+// ingredients
+func sumConsumption(lines []recipeLine, into map[int64]float64) {
+	for _, line := range lines {
+		if line.baseProduct {
+			sumConsumption(line.children, into)
+			continue
+		}
+		into[line.ingredientId] += line.quantity
+	}
+}
+
+// consumptionLine converts the consumption of an ingredient from base units
+// to the unit it is bought in. Packs is the number of purchase quantities,
+// e.g. bags of 10 kg, that cover it. Without a current price the cost is
+// unknown and an ingredient that was never bought stays in base units.
+func consumptionLine(
+	ingredient viewmodels.IngredientWithPrices,
+	quantity float64,
+	units UnitsMap,
+) viewmodels.ConsumptionLine {
+	out := viewmodels.ConsumptionLine{
+		IngredientID: ingredient.Ingredient.ID,
+		Name:         ingredient.Ingredient.Name,
+		Quantity:     quantity,
+	}
+	if len(ingredient.Prices) == 0 {
+		return out
+	}
+	price := ingredient.Prices[0]
+	unit := units[price.UnitID]
+	out.Quantity = quantity * unit.Factor
+	out.Unit = unit.Name
+	out.PackSize = price.Quantity
+	if price.Quantity > 0 {
+		out.Packs = out.Quantity / price.Quantity
+	}
+	if price.Price != nil {
+		out.Cost = utils.Ptr(quantity * *price.Price)
+	}
+	return out
+}
+
+// theoreticalConsumption returns what the given quantities of products
+// should have used of every ingredient in base units
+func (pc *PriceCalcService) theoreticalConsumption(
+	ctx context.Context,
+	products map[int64]float64,
+) (map[int64]float64, error) {
+	out := map[int64]float64{}
+	for productId, quantity := range products {
+		lines, err := pc.recipeLines(ctx, productId, quantity, 0, map[int64]bool{})
+		if err != nil {
+			return nil, err
+		}
+		sumConsumption(lines, out)
+	}
+	return out, nil
+}
+
+// periodConsumption returns the sales and comps from the first to the last
+// day and their theoretical consumption in base units
+func (pc *PriceCalcService) periodConsumption(
+	ctx context.Context,
+	fromDay int64,
+	toDay int64,
+) ([]db.GetSalesInPeriodRow, []db.GetCompsInPeriodRow, map[int64]float64, error) {
+	sales, err := pc.queries.GetSalesInPeriod(ctx, db.GetSalesInPeriodParams{
+		FromDay: fromDay,
+		ToDay:   toDay,
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	comps, err := pc.queries.GetCompsInPeriod(ctx, db.GetCompsInPeriodParams{
+		FromDay: fromDay,
+		ToDay:   toDay,
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	products := map[int64]float64{}
+	for _, sale := range sales {
+		products[sale.ProductID] += sale.Quantity
+	}
+	for _, comp := range comps {
+		products[comp.ProductID] += comp.Quantity
+	}
+	consumption, err := pc.theoreticalConsumption(ctx, products)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return sales, comps, consumption, nil
+}
+
+// GetConsumption reports the sales and comps of every product from the
+// first to the last day and the ingredients they should have used
+func (pc *PriceCalcService) GetConsumption(
+	ctx context.Context,
+	from time.Time,
+	to time.Time,
+) (*viewmodels.ConsumptionViewModel, error) {
+	if to.Before(from) {
+		return nil, errors.New("the period must not end before it starts")
+	}
+	sales, comps, consumption, err := pc.periodConsumption(ctx, saleDay(from), saleDay(to))
+	if err != nil {
+		return nil, err
+	}
+	ingredients, err := pc.GetIngredientsWithPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	units, err := pc.GetUnitsMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := viewmodels.ConsumptionViewModel{
+		From:  saleDay(from),
+		To:    saleDay(to),
+		Sales: sales,
+		Comps: comps,
+		Lines: make([]viewmodels.ConsumptionLine, 0, len(consumption)),
+	}
+	for _, ingredient := range ingredients {
+		quantity, ok := consumption[ingredient.Ingredient.ID]
+		if !ok {
+			continue
+		}
+		line := consumptionLine(ingredient, quantity, units)
+		if line.Cost != nil {
+			out.Cost += *line.Cost
+		} else {
+			out.Unpriced++
+		}
+		out.Lines = append(out.Lines, line)
+	}
+	slices.SortFunc(out.Lines, func(a, b viewmodels.ConsumptionLine) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return &out, nil
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/mike-jl/price_calc/db"
+	"github.com/mike-jl/price_calc/internal/utils"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSumConsumption(t *testing.T) {
+	lines := []recipeLine{
+		{ingredientId: 1, quantity: 0.2},
+		{baseProduct: true, quantity: 2, children: []recipeLine{
+			{ingredientId: 1, quantity: 0.1},
+			{ingredientId: 2, quantity: 0.5},
+		}},
+		{baseProduct: true, quantity: 1, children: []recipeLine{
+			{baseProduct: true, quantity: 1, children: []recipeLine{
+				{ingredientId: 2, quantity: 0.25},
+			}},
+		}},
+	}
+	consumption := map[int64]float64{3: 1}
+	sumConsumption(lines, consumption)
+	assert.InDelta(t, 0.3, consumption[1], 1e-9)
+	assert.InDelta(t, 0.75, consumption[2], 1e-9)
+	assert.Equal(t, 1.0, consumption[3])
+}
+
+func TestConsumptionLine(t *testing.T) {
+	units := UnitsMap{}
+	for _, unit := range testUnits {
+		units[unit.ID] = unit
+	}
+	// bought in bags of 500 g at 4 € per kg
+	ingredient := viewmodels.IngredientWithPrices{
+		Ingredient: db.Ingredient{ID: 7, Name: "Flour"},
+		Prices:     []db.IngredientPrice{{Price: utils.Ptr(4.0), Quantity: 500, UnitID: 11}},
+	}
+
+	line := consumptionLine(ingredient, 1.25, units)
+	assert.Equal(t, int64(7), line.IngredientID)
+	assert.InDelta(t, 1250, line.Quantity, 1e-9)
+	assert.Equal(t, "g", line.Unit)
+	assert.InDelta(t, 2.5, line.Packs, 1e-9)
+	assert.InDelta(t, 5, *line.Cost, 1e-9)
+
+	// a base product price is not a price of its own
+	ingredient.Prices[0].Price = nil
+	line = consumptionLine(ingredient, 1.25, units)
+	assert.InDelta(t, 1250, line.Quantity, 1e-9)
+	assert.Nil(t, line.Cost)
+
+	// never bought, the quantity stays in base units
+	line = consumptionLine(viewmodels.IngredientWithPrices{Ingredient: db.Ingredient{ID: 8, Name: "Salt"}}, 0.2, units)
+	assert.InDelta(t, 0.2, line.Quantity, 1e-9)
+	assert.Empty(t, line.Unit)
+	assert.Nil(t, line.Cost)
+}
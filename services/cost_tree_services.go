@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+// costNodes converts recipe lines to cost nodes with each node's share of
+// total in percent.
+func costNodes(lines []recipeLine, units UnitsMap, total float64) []viewmodels.CostNode {
+	out := make([]viewmodels.CostNode, 0, len(lines))
+	for _, line := range lines {
+		node := viewmodels.CostNode{
+			Name:           line.name,
+			Kind:           "ingredient",
+			ProductID:      line.productId,
+			Quantity:       line.quantity,
+			Unit:           "×",
+			BaseQuantity:   line.quantity,
+			UnitPrice:      line.unitPrice,
+			PriceTimeStamp: line.priceTimeStamp,
+			Duty:           line.duty,
+			Cost:           line.cost,
+			Children:       costNodes(line.children, units, total),
+		}
+		switch {
+		case line.unitId == 0:
+			node.Kind = "bundle_component"
+		case line.baseProduct:
+			node.Kind = "base_product"
+		}
+		if line.unitId != 0 {
+			ingredientId := line.ingredientId
+			node.IngredientID = &ingredientId
+			unit := units[line.unitId]
+			node.Quantity = line.quantity * unit.Factor
+			node.Unit = unit.Name
+			node.BaseUnit = units[line.baseUnitId].Name
+		}
+		if total > 0 {
+			node.Share = line.cost / total * 100
+		}
+		out = append(out, node)
+	}
+	return out
+}
+
+func (pc *PriceCalcService) GetProductCostTree(
+	ctx context.Context,
+	productId int64,
+) (*viewmodels.CostTree, error) {
+	product, err := pc.queries.GetProductWithCost(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+	units, err := pc.GetUnitsMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dutyRate, err := settingFloat(ctx, pc.queries, settingSpiritsDutyRate)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := pc.recipeLines(ctx, productId, 1, dutyRate, map[int64]bool{})
+	if err != nil {
+		return nil, err
+	}
+	tree := viewmodels.CostTree{ProductID: productId, ProductName: product.Name}
+	for _, line := range lines {
+		tree.Cost += line.cost
+	}
+	tree.Nodes = costNodes(lines, units, tree.Cost)
+	return &tree, nil
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCostNodes(t *testing.T) {
+	units := UnitsMap{}
+	for _, unit := range testUnits {
+		units[unit.ID] = unit
+	}
+	colaId, syrupPrice := int64(3), 10.0
+	lines := []recipeLine{
+		{name: "Cola", quantity: 0.5, unitId: 3, baseUnitId: 1, ingredientId: 5, productId: &colaId,
+			cost: 3, baseProduct: true, children: []recipeLine{
+				{name: "Syrup", quantity: 0.3, unitId: 2, baseUnitId: 1, ingredientId: 2,
+					unitPrice: &syrupPrice, cost: 3},
+			}},
+		{name: "Menu", quantity: 2, productId: &colaId, cost: 1, baseProduct: true},
+	}
+
+	nodes := costNodes(lines, units, 4)
+	assert.Len(t, nodes, 2)
+
+	cola := nodes[0]
+	assert.Equal(t, "base_product", cola.Kind)
+	assert.Equal(t, int64(5), *cola.IngredientID)
+	assert.Equal(t, 50.0, cola.Quantity)
+	assert.Equal(t, "cl", cola.Unit)
+	assert.Equal(t, 0.5, cola.BaseQuantity)
+	assert.Equal(t, "l", cola.BaseUnit)
+	assert.Equal(t, 75.0, cola.Share)
+
+	syrup := cola.Children[0]
+	assert.Equal(t, "ingredient", syrup.Kind)
+	assert.Equal(t, 300.0, syrup.Quantity)
+	assert.Equal(t, "ml", syrup.Unit)
+	assert.Equal(t, 10.0, *syrup.UnitPrice)
+	assert.Equal(t, 75.0, syrup.Share)
+	assert.Empty(t, syrup.Children)
+
+	menu := nodes[1]
+	assert.Equal(t, "bundle_component", menu.Kind)
+	assert.Nil(t, menu.IngredientID)
+	assert.Equal(t, "×", menu.Unit)
+	assert.Equal(t, 25.0, menu.Share)
+}
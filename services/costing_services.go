@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/mike-jl/price_calc/db"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+type CostBasis string
+
+const (
+	CostBasisIngredients CostBasis = "ingredients"
+	CostBasisPrime       CostBasis = "prime" // ingredients and labor
+	CostBasisFull        CostBasis = "full"
+)
+
+type OverheadKind string
+
+const (
+	OverheadKindFixed   OverheadKind = "fixed"
+	OverheadKindPercent OverheadKind = "percent"
+)
+
+const (
+	settingLaborRate = "labor_rate"
+	settingCostBasis = "cost_basis"
+)
+
+func (pc *PriceCalcService) GetCostSettings(ctx context.Context) (*viewmodels.CostSettings, error) {
+	settings, err := pc.queries.GetSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := viewmodels.CostSettings{
+		CostBasis:      string(CostBasisIngredients),
+		StockValuation: string(StockValuationLatest),
+	}
+	for _, setting := range settings {
+		switch setting.Key {
+		case settingLaborRate:
+			out.LaborRate, err = strconv.ParseFloat(setting.Value, 64)
+			if err != nil {
+				return nil, err
+			}
+		case settingCostBasis:
+			out.CostBasis = setting.Value
+		case settingStockValuation:
+			out.StockValuation = setting.Value
+		case settingSpiritsDutyRate:
+			out.SpiritsDutyRate, err = strconv.ParseFloat(setting.Value, 64)
+			if err != nil {
+				return nil, err
+			}
+		case settingStandardDrinkGrams:
+			out.StandardDrinkGrams, err = strconv.ParseFloat(setting.Value, 64)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	out.OverheadRules, err = pc.queries.GetOverheadRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+type UpdateCostSettingsParams struct {
+	LaborRate          float64
+	CostBasis          CostBasis
+	StockValuation     StockValuation
+	SpiritsDutyRate    float64
+	StandardDrinkGrams float64
+}
+
+func (pc *PriceCalcService) UpdateCostSettings(
+	ctx context.Context,
+	params UpdateCostSettingsParams,
+) error {
+	if params.LaborRate < 0 {
+		return errors.New("labor rate must not be negative")
+	}
+	if params.SpiritsDutyRate < 0 {
+		return errors.New("spirits duty rate must not be negative")
+	}
+	if params.StandardDrinkGrams <= 0 {
+		return errors.New("standard drink must be greater than 0")
+	}
+	switch params.CostBasis {
+	case CostBasisIngredients, CostBasisPrime, CostBasisFull:
+	default:
+		return errors.New("unknown cost basis")
+	}
+	switch params.StockValuation {
+	case StockValuationLatest, StockValuationAverage, StockValuationFifo:
+	default:
+		return errors.New("unknown stock valuation")
+	}
+
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	oldDutyRate, err := settingFloat(ctx, qtx, settingSpiritsDutyRate)
+	if err != nil {
+		return err
+	}
+
+	settings := []db.PutSettingParams{
+		{Key: settingLaborRate, Value: strconv.FormatFloat(params.LaborRate, 'f', -1, 64)},
+		{Key: settingCostBasis, Value: string(params.CostBasis)},
+		{Key: settingStockValuation, Value: string(params.StockValuation)},
+		{Key: settingSpiritsDutyRate, Value: strconv.FormatFloat(params.SpiritsDutyRate, 'f', -1, 64)},
+		{
+			Key:   settingStandardDrinkGrams,
+			Value: strconv.FormatFloat(params.StandardDrinkGrams, 'f', -1, 64),
+		},
+	}
+	for _, setting := range settings {
+		err = qtx.PutSetting(ctx, setting)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the duty is part of the cached ingredient cost
+	if oldDutyRate != params.SpiritsDutyRate {
+		err = pc.updateAllProductCosts(ctx, qtx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (pc *PriceCalcService) updateAllProductCosts(ctx context.Context, qtx *db.Queries) error {
+	products, err := qtx.GetProductNames(ctx)
+	if err != nil {
+		return err
+	}
+	for _, product := range products {
+		_, err = pc.UpdateProductCost(ctx, qtx, product.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (pc *PriceCalcService) PutOverheadRule(
+	ctx context.Context,
+	name string,
+	kind OverheadKind,
+	amount float64,
+	categoryId *int64,
+) (*db.OverheadRule, error) {
+	if name == "" {
+		return nil, errors.New("overhead rule name is empty")
+	}
+	if kind != OverheadKindFixed && kind != OverheadKindPercent {
+		return nil, errors.New("unknown overhead kind")
+	}
+	if amount < 0 {
+		return nil, errors.New("overhead amount must not be negative")
+	}
+	rule, err := pc.queries.PutOverheadRule(ctx, db.PutOverheadRuleParams{
+		Name:       name,
+		Kind:       string(kind),
+		Amount:     amount,
+		CategoryID: categoryId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (pc *PriceCalcService) DeleteOverheadRule(ctx context.Context, id int64) error {
+	num, err := pc.queries.DeleteOverheadRule(ctx, id)
+	if err != nil {
+		return err
+	}
+	if num < 1 {
+		return ErrNoRowsAffected
+	}
+	return nil
+}
+
+// calculateCostBreakdown adds labor and overhead to the ingredient cost of a product.
+// Percentage overhead is charged on the ingredient cost only.
+func calculateCostBreakdown(
+	product db.Product,
+	ingredientCost float64,
+	settings viewmodels.CostSettings,
+) viewmodels.CostBreakdown {
+	breakdown := viewmodels.CostBreakdown{
+		Ingredients: ingredientCost,
+		Labor:       product.PrepMinutes / 60 * settings.LaborRate,
+	}
+
+	for _, rule := range settings.OverheadRules {
+		if rule.CategoryID != nil && *rule.CategoryID != product.CategoryID {
+			continue
+		}
+		switch OverheadKind(rule.Kind) {
+		case OverheadKindFixed:
+			breakdown.Overhead += rule.Amount
+		case OverheadKindPercent:
+			breakdown.Overhead += ingredientCost * rule.Amount / 100
+		}
+	}
+
+	breakdown.Full = breakdown.Ingredients + breakdown.Labor + breakdown.Overhead
+
+	switch CostBasis(settings.CostBasis) {
+	case CostBasisPrime:
+		breakdown.Basis = breakdown.Ingredients + breakdown.Labor
+	case CostBasisFull:
+		breakdown.Basis = breakdown.Full
+	default:
+		breakdown.Basis = breakdown.Ingredients
+	}
+
+	return breakdown
+}
+
+func (pc *PriceCalcService) addCostBreakdowns(
+	ctx context.Context,
+	products []viewmodels.ProductWithCost,
+) error {
+	settings, err := pc.GetCostSettings(ctx)
+	if err != nil {
+		return err
+	}
+	for i, product := range products {
+		products[i].Breakdown = calculateCostBreakdown(product.Product, product.Cost, *settings)
+	}
+	return nil
+}
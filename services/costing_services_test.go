@@ -0,0 +1,69 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/mike-jl/price_calc/db"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateCostBreakdown(t *testing.T) {
+	foodCategory := int64(1)
+	drinksCategory := int64(2)
+	rules := []db.OverheadRule{
+		{ID: 1, Name: "Energy", Kind: "fixed", Amount: 0.3},
+		{ID: 2, Name: "Cleaning", Kind: "percent", Amount: 10, CategoryID: &foodCategory},
+		{ID: 3, Name: "Glassware", Kind: "fixed", Amount: 0.2, CategoryID: &drinksCategory},
+	}
+	product := db.Product{ID: 1, Name: "Burger", CategoryID: foodCategory, PrepMinutes: 6}
+
+	tests := []struct {
+		name             string
+		costBasis        CostBasis
+		expectedLabor    float64
+		expectedOverhead float64
+		expectedFull     float64
+		expectedBasis    float64
+	}{
+		{
+			name:             "Multiplicator on ingredient cost",
+			costBasis:        CostBasisIngredients,
+			expectedLabor:    1.5,
+			expectedOverhead: 0.6,
+			expectedFull:     5.1,
+			expectedBasis:    3,
+		},
+		{
+			name:             "Multiplicator on ingredients and labor",
+			costBasis:        CostBasisPrime,
+			expectedLabor:    1.5,
+			expectedOverhead: 0.6,
+			expectedFull:     5.1,
+			expectedBasis:    4.5,
+		},
+		{
+			name:             "Multiplicator on full cost",
+			costBasis:        CostBasisFull,
+			expectedLabor:    1.5,
+			expectedOverhead: 0.6,
+			expectedFull:     5.1,
+			expectedBasis:    5.1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breakdown := calculateCostBreakdown(product, 3, viewmodels.CostSettings{
+				LaborRate:     15,
+				CostBasis:     string(tt.costBasis),
+				OverheadRules: rules,
+			})
+			assert.InDelta(t, 3, breakdown.Ingredients, 0.0001)
+			assert.InDelta(t, tt.expectedLabor, breakdown.Labor, 0.0001)
+			assert.InDelta(t, tt.expectedOverhead, breakdown.Overhead, 0.0001)
+			assert.InDelta(t, tt.expectedFull, breakdown.Full, 0.0001)
+			assert.InDelta(t, tt.expectedBasis, breakdown.Basis, 0.0001)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mike-jl/price_calc/db"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+type MenuClass string
+
+const (
+	MenuClassStar      MenuClass = "star"      // popular and profitable
+	MenuClassPlowhorse MenuClass = "plowhorse" // popular, low margin
+	MenuClassPuzzle    MenuClass = "puzzle"    // profitable, rarely sold
+	MenuClassDog       MenuClass = "dog"       // neither
+)
+
+// menuPopularityFactor is the share of an even menu mix a product has to
+// reach to count as popular
+const menuPopularityFactor = 0.7
+
+// menuClass places a product in the menu engineering matrix
+func menuClass(popular bool, profitable bool) MenuClass {
+	switch {
+	case popular && profitable:
+		return MenuClassStar
+	case popular:
+		return MenuClassPlowhorse
+	case profitable:
+		return MenuClassPuzzle
+	}
+	return MenuClassDog
+}
+
+// menuEngineering classifies the products of one category. A product is
+// popular if its menu mix reaches 70% of an even mix and profitable if its
+// contribution margin reaches the average margin weighted by the sales.
+func menuEngineering(items []viewmodels.MenuEngineeringItem) viewmodels.MenuEngineeringCategory {
+	out := viewmodels.MenuEngineeringCategory{Items: items}
+	for i := range out.Items {
+		item := &out.Items[i]
+		item.Margin = item.NetPrice - item.Cost
+		item.TotalMargin = item.Margin * item.Sold
+		out.Sold += item.Sold
+		out.TotalMargin += item.TotalMargin
+	}
+	if len(items) == 0 {
+		return out
+	}
+	if out.Sold == 0 {
+		// nothing sold, so nothing is popular or earned a margin
+		for i := range out.Items {
+			out.Items[i].Class = string(MenuClassDog)
+		}
+		return out
+	}
+	out.MixThreshold = 100 / float64(len(items)) * menuPopularityFactor
+	out.AverageMargin = out.TotalMargin / out.Sold
+
+	for i := range out.Items {
+		item := &out.Items[i]
+		item.MenuMix = item.Sold / out.Sold * 100
+		item.Class = string(menuClass(
+			item.MenuMix >= out.MixThreshold,
+			item.Margin >= out.AverageMargin,
+		))
+	}
+	slices.SortFunc(out.Items, func(a, b viewmodels.MenuEngineeringItem) int {
+		return cmp.Or(cmp.Compare(b.TotalMargin, a.TotalMargin), strings.Compare(a.Name, b.Name))
+	})
+	return out
+}
+
+// GetMenuEngineering classifies the products by their sales from the first to
+// the last day by category. The margin is the price without dine-in VAT less the
+// cost of the ingredients, categoryId limits the report to one category.
+func (pc *PriceCalcService) GetMenuEngineering(
+	ctx context.Context,
+	from time.Time,
+	to time.Time,
+	categoryId *int64,
+) (*viewmodels.MenuEngineeringViewModel, error) {
+	if to.Before(from) {
+		return nil, errors.New("the period must not end before it starts")
+	}
+	sales, err := pc.queries.GetSalesInPeriod(ctx, db.GetSalesInPeriodParams{
+		FromDay: saleDay(from),
+		ToDay:   saleDay(to),
+	})
+	if err != nil {
+		return nil, err
+	}
+	products, err := pc.GetProductsWithCost()
+	if err != nil {
+		return nil, err
+	}
+	categories, err := pc.GetCategoriesWithVat(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+
+	out := viewmodels.MenuEngineeringViewModel{
+		From:       saleDay(from),
+		To:         saleDay(to),
+		CategoryID: categoryId,
+		Categories: make(map[int64]string, len(categories)),
+		Sections:   []viewmodels.MenuEngineeringCategory{},
+	}
+	sold := make(map[int64]float64, len(sales))
+	for _, sale := range sales {
+		sold[sale.ProductID] = sale.Quantity
+	}
+	// every product is classified, the ones that did not sell as well
+	itemsByCategory := map[int64][]viewmodels.MenuEngineeringItem{}
+	for _, product := range products {
+		categoryID := product.Product.CategoryID
+		if categoryId != nil && categoryID != *categoryId {
+			continue
+		}
+		itemsByCategory[categoryID] = append(itemsByCategory[categoryID], viewmodels.MenuEngineeringItem{
+			ProductID: product.Product.ID,
+			Name:      product.Product.Name,
+			Sold:      sold[product.Product.ID],
+			Price:     product.Product.Price,
+			Cost:      product.Cost,
+		})
+	}
+
+	for _, category := range categories {
+		out.Categories[category.ID] = category.Name
+		items, ok := itemsByCategory[category.ID]
+		if !ok {
+			continue
+		}
+		for i := range items {
+			items[i].NetPrice = items[i].Price / (1 + category.DineInVat/100)
+		}
+		section := menuEngineering(items)
+		section.CategoryID = category.ID
+		section.Name = category.Name
+		out.Sections = append(out.Sections, section)
+	}
+	slices.SortFunc(out.Sections, func(a, b viewmodels.MenuEngineeringCategory) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return &out, nil
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"testing"
+
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMenuClass(t *testing.T) {
+	assert.Equal(t, MenuClassStar, menuClass(true, true))
+	assert.Equal(t, MenuClassPlowhorse, menuClass(true, false))
+	assert.Equal(t, MenuClassPuzzle, menuClass(false, true))
+	assert.Equal(t, MenuClassDog, menuClass(false, false))
+}
+
+func TestMenuEngineering(t *testing.T) {
+	section := menuEngineering([]viewmodels.MenuEngineeringItem{
+		{Name: "Burger", Sold: 50, NetPrice: 10, Cost: 4},
+		{Name: "Fries", Sold: 40, NetPrice: 4, Cost: 1},
+		{Name: "Steak", Sold: 5, NetPrice: 25, Cost: 12},
+		{Name: "Salad", Sold: 5, NetPrice: 6, Cost: 3},
+	})
+
+	// 4 products, popular from 70% of 25%
+	assert.InDelta(t, 17.5, section.MixThreshold, 1e-9)
+	// (50*6 + 40*3 + 5*13 + 5*3) / 100
+	assert.InDelta(t, 5, section.AverageMargin, 1e-9)
+	assert.InDelta(t, 500, section.TotalMargin, 1e-9)
+	assert.Equal(t, 100.0, section.Sold)
+
+	classes := map[string]string{}
+	for _, item := range section.Items {
+		classes[item.Name] = item.Class
+	}
+	assert.Equal(t, map[string]string{
+		"Burger": "star",
+		"Fries":  "plowhorse",
+		"Steak":  "puzzle",
+		"Salad":  "dog",
+	}, classes)
+	// sorted by total margin
+	assert.Equal(t, "Burger", section.Items[0].Name)
+	assert.InDelta(t, 50, section.Items[0].MenuMix, 1e-9)
+}
+
+func TestMenuEngineeringWithoutSales(t *testing.T) {
+	section := menuEngineering([]viewmodels.MenuEngineeringItem{{Name: "Burger", NetPrice: 10, Cost: 4}})
+	assert.Equal(t, 0.0, section.MixThreshold)
+	assert.Equal(t, "dog", section.Items[0].Class)
+}
+
+func TestMenuEngineeringUnsold(t *testing.T) {
+	section := menuEngineering([]viewmodels.MenuEngineeringItem{
+		{Name: "Burger", Sold: 60, NetPrice: 10, Cost: 4},
+		{Name: "Fries", Sold: 40, NetPrice: 4, Cost: 1},
+		{Name: "Soup", NetPrice: 5, Cost: 2},
+	})
+
+	// the unsold product counts for the even mix
+	assert.InDelta(t, 100.0/3*0.7, section.MixThreshold, 1e-9)
+	assert.Equal(t, "Soup", section.Items[2].Name)
+	assert.Equal(t, 0.0, section.Items[2].MenuMix)
+	assert.Equal(t, "dog", section.Items[2].Class)
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mike-jl/price_calc/db"
+	"github.com/mike-jl/price_calc/internal/utils"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+// nutritionUsage is one line of a recipe as far as nutrition is concerned.
+// Usages of base products carry the nutrition of one unit of that product.
+type nutritionUsage struct {
+	name     string
+	quantity float64
+	// weight is set if the quantity is in kg or l
+	weight      bool
+	per100      *viewmodels.NutritionFacts
+	baseProduct *viewmodels.ProductNutrition
+}
+
+func addNutrition(total *viewmodels.NutritionFacts, facts viewmodels.NutritionFacts, factor float64) {
+	total.Kcal += facts.Kcal * factor
+	total.Fat += facts.Fat * factor
+	total.Carbs += facts.Carbs * factor
+	total.Sugar += facts.Sugar * factor
+	total.Protein += facts.Protein * factor
+	total.Salt += facts.Salt * factor
+}
+
+func summarizeNutrition(usages []nutritionUsage, servings float64) viewmodels.ProductNutrition {
+	out := viewmodels.ProductNutrition{Servings: servings, Missing: []string{}}
+	for _, usage := range usages {
+		if usage.baseProduct != nil {
+			addNutrition(&out.Total, usage.baseProduct.Total, usage.quantity)
+			out.Weight += usage.baseProduct.Weight * usage.quantity
+			out.Missing = append(out.Missing, usage.baseProduct.Missing...)
+			continue
+		}
+		if usage.per100 == nil || !usage.weight {
+			out.Missing = append(out.Missing, usage.name)
+			continue
+		}
+		// values are per 100 g or ml, quantities in kg or l
+		addNutrition(&out.Total, *usage.per100, usage.quantity*10)
+		out.Weight += usage.quantity
+	}
+
+	if servings > 0 {
+		addNutrition(&out.PerServing, out.Total, 1/servings)
+	}
+	if out.Weight > 0 {
+		addNutrition(&out.Per100, out.Total, 0.1/out.Weight)
+	}
+	return out
+}
+
+func validateNutrition(facts viewmodels.NutritionFacts) error {
+	if facts.Kcal < 0 || facts.Fat < 0 || facts.Carbs < 0 ||
+		facts.Sugar < 0 || facts.Protein < 0 || facts.Salt < 0 {
+		return errors.New("nutrition values must not be negative")
+	}
+	if facts.Sugar > facts.Carbs {
+		return errors.New("sugar must not be more than carbs")
+	}
+	if facts.Fat+facts.Carbs+facts.Protein+facts.Salt > 100 {
+		return errors.New("nutrition values must not add up to more than 100 g")
+	}
+	return nil
+}
+
+func (pc *PriceCalcService) getIngredientNutritionMap(
+	ctx context.Context,
+) (map[int64]db.IngredientNutrition, error) {
+	rows, err := pc.queries.GetIngredientNutrition(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int64]db.IngredientNutrition, len(rows))
+	for _, row := range rows {
+		out[row.IngredientID] = row
+	}
+	return out, nil
+}
+
+func (pc *PriceCalcService) GetIngredientNutritionRows(
+	ctx context.Context,
+) ([]viewmodels.IngredientNutritionRow, error) {
+	ingredients, err := pc.GetIngredientsWithPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nutrition, err := pc.getIngredientNutritionMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]viewmodels.IngredientNutritionRow, 0, len(ingredients))
+	for _, ingredient := range ingredients {
+		// products used as ingredients get their nutrition from their recipe
+		if len(ingredient.Prices) > 0 && ingredient.Prices[0].BaseProductID != nil {
+			continue
+		}
+		row := viewmodels.IngredientNutritionRow{
+			ID:   ingredient.Ingredient.ID,
+			Name: ingredient.Ingredient.Name,
+		}
+		if facts, ok := nutrition[ingredient.Ingredient.ID]; ok {
+			row.Nutrition = &facts
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
+
+func (pc *PriceCalcService) GetIngredientNutritionRow(
+	ctx context.Context,
+	ingredientId int64,
+) (*viewmodels.IngredientNutritionRow, error) {
+	rows, err := pc.GetIngredientNutritionRows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if row.ID == ingredientId {
+			return &row, nil
+		}
+	}
+	return nil, fmt.Errorf("ingredient with id %d not found", ingredientId)
+}
+
+func (pc *PriceCalcService) PutIngredientNutrition(
+	ctx context.Context,
+	ingredientId int64,
+	facts viewmodels.NutritionFacts,
+) error {
+	err := validateNutrition(facts)
+	if err != nil {
+		return err
+	}
+	_, err = pc.GetIngredientNutritionRow(ctx, ingredientId)
+	if err != nil {
+		return err
+	}
+	_, err = pc.queries.PutIngredientNutrition(ctx, db.PutIngredientNutritionParams{
+		IngredientID: ingredientId,
+		Kcal:         facts.Kcal,
+		Fat:          facts.Fat,
+		Carbs:        facts.Carbs,
+		Sugar:        facts.Sugar,
+		Protein:      facts.Protein,
+		Salt:         facts.Salt,
+	})
+	return err
+}
+
+func (pc *PriceCalcService) GetProductNutrition(
+	ctx context.Context,
+	productId int64,
+) (*viewmodels.ProductNutrition, error) {
+	nutrition, err := pc.getIngredientNutritionMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	product, err := pc.calculateProductNutrition(ctx, productId, nutrition, map[int64]bool{})
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (pc *PriceCalcService) calculateProductNutrition(
+	ctx context.Context,
+	productId int64,
+	nutrition map[int64]db.IngredientNutrition,
+	visited map[int64]bool,
+) (viewmodels.ProductNutrition, error) {
+	if visited[productId] {
+		return viewmodels.ProductNutrition{}, fmt.Errorf("circular dependency detected on product %d", productId)
+	}
+	visited[productId] = true
+	defer delete(visited, productId)
+
+	product, err := pc.queries.GetProductWithCost(ctx, productId)
+	if err != nil {
+		return viewmodels.ProductNutrition{}, err
+	}
+
+	rows, err := pc.queries.GetIngredientUsageForProductWithPrice(ctx, productId)
+	if err != nil {
+		return viewmodels.ProductNutrition{}, err
+	}
+
+	usages := make([]nutritionUsage, 0, len(rows))
+	for _, row := range rows {
+		usage := nutritionUsage{
+			name:     utils.Deref(row.Name),
+			quantity: row.Quantity,
+			weight:   isVolumeUnit(row.BaseUnitID),
+		}
+		if row.BaseProductID != nil {
+			sub, err := pc.calculateProductNutrition(ctx, *row.BaseProductID, nutrition, visited)
+			if err != nil {
+				return viewmodels.ProductNutrition{}, err
+			}
+			usage.baseProduct = &sub
+		} else if facts, ok := nutrition[row.IngredientID]; ok {
+			usage.per100 = &viewmodels.NutritionFacts{
+				Kcal:    facts.Kcal,
+				Fat:     facts.Fat,
+				Carbs:   facts.Carbs,
+				Sugar:   facts.Sugar,
+				Protein: facts.Protein,
+				Salt:    facts.Salt,
+			}
+		}
+		usages = append(usages, usage)
+	}
+
+	components, err := pc.queries.GetBundleComponents(ctx, productId)
+	if err != nil {
+		return viewmodels.ProductNutrition{}, err
+	}
+	for _, component := range components {
+		sub, err := pc.calculateProductNutrition(ctx, component.ProductID, nutrition, visited)
+		if err != nil {
+			return viewmodels.ProductNutrition{}, err
+		}
+		usages = append(usages, nutritionUsage{quantity: component.Quantity, baseProduct: &sub})
+	}
+
+	return summarizeNutrition(usages, product.Servings), nil
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"testing"
+
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeNutrition(t *testing.T) {
+	flour := viewmodels.NutritionFacts{Kcal: 350, Fat: 1, Carbs: 72, Sugar: 1, Protein: 10, Salt: 0}
+	milk := viewmodels.NutritionFacts{Kcal: 64, Fat: 3.5, Carbs: 4.8, Sugar: 4.8, Protein: 3.3, Salt: 0.1}
+
+	dough := summarizeNutrition([]nutritionUsage{
+		{name: "Flour", quantity: 0.5, weight: true, per100: &flour},
+		{name: "Milk", quantity: 0.5, weight: true, per100: &milk},
+		// eggs in pieces can not be converted
+		{name: "Egg", quantity: 2, weight: false},
+		{name: "Vanilla", quantity: 0.005, weight: true},
+	}, 4)
+
+	assert.InDelta(t, 1.0, dough.Weight, 0.0001)
+	assert.InDelta(t, 2070, dough.Total.Kcal, 0.0001)
+	assert.InDelta(t, 384, dough.Total.Carbs, 0.0001)
+	assert.InDelta(t, 517.5, dough.PerServing.Kcal, 0.0001)
+	assert.InDelta(t, 207, dough.Per100.Kcal, 0.0001)
+	assert.InDelta(t, 0.05, dough.Per100.Salt, 0.0001)
+	assert.Equal(t, []string{"Egg", "Vanilla"}, dough.Missing)
+
+	// half of the dough used in another product
+	pancakes := summarizeNutrition([]nutritionUsage{
+		{quantity: 0.5, baseProduct: &dough},
+	}, 1)
+
+	assert.InDelta(t, 0.5, pancakes.Weight, 0.0001)
+	assert.InDelta(t, 1035, pancakes.Total.Kcal, 0.0001)
+	assert.InDelta(t, 1035, pancakes.PerServing.Kcal, 0.0001)
+	assert.InDelta(t, 207, pancakes.Per100.Kcal, 0.0001)
+	assert.Equal(t, []string{"Egg", "Vanilla"}, pancakes.Missing)
+}
+
+func TestValidateNutrition(t *testing.T) {
+	assert.NoError(t, validateNutrition(viewmodels.NutritionFacts{Kcal: 884, Fat: 100}))
+	assert.Error(t, validateNutrition(viewmodels.NutritionFacts{Fat: -1}))
+	assert.Error(t, validateNutrition(viewmodels.NutritionFacts{Carbs: 5, Sugar: 10}))
+	assert.Error(t, validateNutrition(viewmodels.NutritionFacts{Fat: 60, Carbs: 60}))
+}
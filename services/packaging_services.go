@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mike-jl/price_calc/db"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+func packagingItemCost(row db.GetChannelPackagingRow) (float64, error) {
+	if row.Price != nil {
+		return *row.Price * row.Quantity, nil
+	}
+	if row.BaseProductCost != nil {
+		return *row.BaseProductCost * row.Quantity, nil
+	}
+	return 0, fmt.Errorf("no price found for ingredient %d", row.IngredientID)
+}
+
+// getChannelPackaging returns the costed packaging items of all products,
+// grouped by product and channel.
+func (pc *PriceCalcService) getChannelPackaging(
+	ctx context.Context,
+) (map[productChannelKey][]viewmodels.PackagingItem, error) {
+	rows, err := pc.queries.GetChannelPackaging(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[productChannelKey][]viewmodels.PackagingItem)
+	for _, row := range rows {
+		cost, err := packagingItemCost(row)
+		if err != nil {
+			return nil, err
+		}
+		key := productChannelKey{row.ProductID, row.ChannelID}
+		out[key] = append(out[key], viewmodels.PackagingItem{
+			ID:           row.ID,
+			IngredientID: row.IngredientID,
+			Name:         row.Name,
+			Quantity:     row.Quantity,
+			UnitID:       row.UnitID,
+			Cost:         cost,
+		})
+	}
+	return out, nil
+}
+
+func (pc *PriceCalcService) PutChannelPackaging(
+	ctx context.Context,
+	productId, channelId, ingredientId, unitId int64,
+	amount float64,
+) (*db.ProductChannelPackaging, error) {
+	if amount <= 0 {
+		return nil, errors.New("amount must be greater than 0")
+	}
+
+	// foreign keys are not enforced, so make sure everything exists
+	_, err := pc.queries.GetProductWithCost(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+	_, err = pc.queries.GetSalesChannel(ctx, channelId)
+	if err != nil {
+		return nil, err
+	}
+	_, err = pc.queries.GetIngredient(ctx, ingredientId)
+	if err != nil {
+		return nil, err
+	}
+	unit, err := pc.queries.GetUnit(ctx, unitId)
+	if err != nil {
+		return nil, err
+	}
+
+	packaging, err := pc.queries.PutChannelPackaging(ctx, db.PutChannelPackagingParams{
+		ProductID:    productId,
+		ChannelID:    channelId,
+		IngredientID: ingredientId,
+		Quantity:     amount / unit.Factor,
+		UnitID:       unitId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &packaging, nil
+}
+
+func (pc *PriceCalcService) DeleteChannelPackaging(ctx context.Context, id int64) error {
+	num, err := pc.queries.DeleteChannelPackaging(ctx, id)
+	if err != nil {
+		return err
+	}
+	if num < 1 {
+		return ErrNoRowsAffected
+	}
+	return nil
+}
@@ -0,0 +1,397 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mike-jl/price_calc/db"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+type SaleSource string
+
+const (
+	SaleSourcePos    SaleSource = "pos"
+	SaleSourceManual SaleSource = "manual"
+)
+
+const (
+	settingPosDelimiter      = "pos_delimiter"
+	settingPosDateFormat     = "pos_date_format"
+	settingPosColumnDate     = "pos_column_date"
+	settingPosColumnItem     = "pos_column_item"
+	settingPosColumnName     = "pos_column_name"
+	settingPosColumnQuantity = "pos_column_quantity"
+	settingPosColumnRevenue  = "pos_column_revenue"
+)
+
+// PosDateFormats are the date formats a POS export can use
+var PosDateFormats = map[string]string{
+	"YYYY-MM-DD": time.DateOnly,
+	"DD.MM.YYYY": "02.01.2006",
+	"DD/MM/YYYY": "02/01/2006",
+	"MM/DD/YYYY": "01/02/2006",
+}
+
+// PosDelimiters are the column separators a POS export can use
+var PosDelimiters = map[string]rune{
+	",":   ',',
+	";":   ';',
+	"tab": '\t',
+}
+
+func (pc *PriceCalcService) GetPosSettings(ctx context.Context) (*viewmodels.PosSettings, error) {
+	settings, err := pc.queries.GetSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := viewmodels.PosSettings{}
+	fields := map[string]*string{
+		settingPosDelimiter:      &out.Delimiter,
+		settingPosDateFormat:     &out.DateFormat,
+		settingPosColumnDate:     &out.DateColumn,
+		settingPosColumnItem:     &out.ItemColumn,
+		settingPosColumnName:     &out.NameColumn,
+		settingPosColumnQuantity: &out.QuantityColumn,
+		settingPosColumnRevenue:  &out.RevenueColumn,
+	}
+	for _, setting := range settings {
+		if field, ok := fields[setting.Key]; ok {
+			*field = setting.Value
+		}
+	}
+	return &out, nil
+}
+
+func validatePosSettings(settings viewmodels.PosSettings) error {
+	if _, ok := PosDelimiters[settings.Delimiter]; !ok {
+		return errors.New("unknown delimiter")
+	}
+	if _, ok := PosDateFormats[settings.DateFormat]; !ok {
+		return errors.New("unknown date format")
+	}
+	if settings.DateColumn == "" || settings.ItemColumn == "" || settings.QuantityColumn == "" {
+		return errors.New("date, item and quantity column are required")
+	}
+	return nil
+}
+
+func (pc *PriceCalcService) UpdatePosSettings(ctx context.Context, settings viewmodels.PosSettings) error {
+	err := validatePosSettings(settings)
+	if err != nil {
+		return err
+	}
+
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	params := []db.PutSettingParams{
+		{Key: settingPosDelimiter, Value: settings.Delimiter},
+		{Key: settingPosDateFormat, Value: settings.DateFormat},
+		{Key: settingPosColumnDate, Value: strings.TrimSpace(settings.DateColumn)},
+		{Key: settingPosColumnItem, Value: strings.TrimSpace(settings.ItemColumn)},
+		{Key: settingPosColumnName, Value: strings.TrimSpace(settings.NameColumn)},
+		{Key: settingPosColumnQuantity, Value: strings.TrimSpace(settings.QuantityColumn)},
+		{Key: settingPosColumnRevenue, Value: strings.TrimSpace(settings.RevenueColumn)},
+	}
+	for _, param := range params {
+		err = qtx.PutSetting(ctx, param)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// posLine is one row of a POS export, day is the start of the day in UTC
+type posLine struct {
+	item     string
+	name     string
+	day      int64
+	quantity float64
+	revenue  *float64
+}
+
+// parsePosNumber accepts decimal commas and thousands separators, e.g.
+// 1.234,50 and 1,234.50
+func parsePosNumber(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	comma, dot := strings.LastIndex(value, ","), strings.LastIndex(value, ".")
+	if comma > dot {
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.Replace(value, ",", ".", 1)
+	} else {
+		value = strings.ReplaceAll(value, ",", "")
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// parsePosCsv reads a POS export with a header row. Rows without an item,
+// like totals, are skipped.
+func parsePosCsv(r io.Reader, settings viewmodels.PosSettings) ([]posLine, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = PosDelimiters[settings.Delimiter]
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("could not read header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	// optional columns that are not configured or missing are -1
+	column := func(name string) int {
+		if i, ok := columns[strings.ToLower(strings.TrimSpace(name))]; ok && name != "" {
+			return i
+		}
+		return -1
+	}
+	for _, name := range []string{settings.DateColumn, settings.ItemColumn, settings.QuantityColumn} {
+		if column(name) < 0 {
+			return nil, fmt.Errorf("column %q not found", name)
+		}
+	}
+	dateColumn, itemColumn := column(settings.DateColumn), column(settings.ItemColumn)
+	quantityColumn := column(settings.QuantityColumn)
+	nameColumn, revenueColumn := column(settings.NameColumn), column(settings.RevenueColumn)
+	field := func(record []string, i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	out := []posLine{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		lineNumber, _ := reader.FieldPos(0)
+		line := posLine{item: field(record, itemColumn), name: field(record, nameColumn)}
+		if line.item == "" {
+			continue
+		}
+
+		date := strings.Fields(field(record, dateColumn))
+		if len(date) == 0 {
+			return nil, fmt.Errorf("line %d: date is empty", lineNumber)
+		}
+		day, err := time.Parse(PosDateFormats[settings.DateFormat], date[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		line.day = day.Unix()
+
+		line.quantity, err = parsePosNumber(field(record, quantityColumn))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		if revenue := field(record, revenueColumn); revenue != "" {
+			parsed, err := parsePosNumber(revenue)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			line.revenue = &parsed
+		}
+		out = append(out, line)
+	}
+	return out, nil
+}
+
+// aggregatePosSales sums the lines per product and day. Lines of items
+// without a product are returned by item.
+func aggregatePosSales(
+	lines []posLine,
+	products map[string]*int64,
+) ([]db.InsertSaleParams, []string) {
+	type key struct{ productId, day int64 }
+	totals := map[key]*db.InsertSaleParams{}
+	unmapped := []string{}
+	for _, line := range lines {
+		productId := products[line.item]
+		if productId == nil {
+			if !slices.Contains(unmapped, line.item) {
+				unmapped = append(unmapped, line.item)
+			}
+			continue
+		}
+		total, ok := totals[key{*productId, line.day}]
+		if !ok {
+			total = &db.InsertSaleParams{
+				ProductID: *productId,
+				SoldOn:    line.day,
+				Source:    string(SaleSourcePos),
+			}
+			totals[key{*productId, line.day}] = total
+		}
+		total.Quantity += line.quantity
+		if line.revenue != nil {
+			if total.Revenue == nil {
+				total.Revenue = new(float64)
+			}
+			*total.Revenue += *line.revenue
+		}
+	}
+
+	out := make([]db.InsertSaleParams, 0, len(totals))
+	for _, total := range totals {
+		out = append(out, *total)
+	}
+	slices.SortFunc(out, func(a, b db.InsertSaleParams) int {
+		return cmp.Or(cmp.Compare(a.SoldOn, b.SoldOn), cmp.Compare(a.ProductID, b.ProductID))
+	})
+	return out, unmapped
+}
+
+// ImportPosSales replaces the POS sales of every day from the first to the
+// last day of the export, so importing the same export again changes nothing.
+// New items are remembered without a product for mapping.
+func (pc *PriceCalcService) ImportPosSales(
+	ctx context.Context,
+	r io.Reader,
+) (*viewmodels.PosImportResult, error) {
+	settings, err := pc.GetPosSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = validatePosSettings(*settings)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := parsePosCsv(r, *settings)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("the export contains no sales")
+	}
+
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	items, err := qtx.GetPosItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	products := make(map[string]*int64, len(items))
+	for _, item := range items {
+		products[item.Item] = item.ProductID
+	}
+
+	out := viewmodels.PosImportResult{From: lines[0].day, To: lines[0].day, Lines: len(lines)}
+	for _, line := range lines {
+		out.From = min(out.From, line.day)
+		out.To = max(out.To, line.day)
+		if _, ok := products[line.item]; ok {
+			continue
+		}
+		_, err = qtx.InsertPosItem(ctx, db.InsertPosItemParams{Item: line.item, Name: line.name})
+		if err != nil {
+			return nil, err
+		}
+		products[line.item] = nil
+	}
+
+	_, err = qtx.DeleteSalesInPeriod(ctx, db.DeleteSalesInPeriodParams{
+		Source:  string(SaleSourcePos),
+		FromDay: out.From,
+		ToDay:   out.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sales, unmapped := aggregatePosSales(lines, products)
+	for _, sale := range sales {
+		_, err = qtx.InsertSale(ctx, sale)
+		if err != nil {
+			return nil, err
+		}
+	}
+	out.Sales = len(sales)
+	out.Unmapped = unmapped
+	return &out, tx.Commit()
+}
+
+func (pc *PriceCalcService) GetPosImport(ctx context.Context) (*viewmodels.PosImportViewModel, error) {
+	settings, err := pc.GetPosSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items, err := pc.queries.GetPosItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	products, err := pc.GetProductNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &viewmodels.PosImportViewModel{
+		Settings: *settings,
+		Items:    items,
+		Products: products,
+	}, nil
+}
+
+// SetPosItemProduct maps a POS item to a product, nil removes the mapping.
+// Sales already imported are not changed until the period is imported again.
+func (pc *PriceCalcService) SetPosItemProduct(
+	ctx context.Context,
+	itemId int64,
+	productId *int64,
+) error {
+	if productId != nil {
+		_, err := pc.queries.GetProductWithCost(ctx, *productId)
+		if err != nil {
+			return err
+		}
+	}
+	num, err := pc.queries.SetPosItemProduct(ctx, db.SetPosItemProductParams{
+		ProductID: productId,
+		ID:        itemId,
+	})
+	if err != nil {
+		return err
+	}
+	if num < 1 {
+		return ErrNoRowsAffected
+	}
+	return nil
+}
+
+func (pc *PriceCalcService) DeletePosItem(ctx context.Context, itemId int64) error {
+	num, err := pc.queries.DeletePosItem(ctx, itemId)
+	if err != nil {
+		return err
+	}
+	if num < 1 {
+		return ErrNoRowsAffected
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mike-jl/price_calc/internal/utils"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePosNumber(t *testing.T) {
+	for value, expected := range map[string]float64{
+		"12":       12,
+		"12.5":     12.5,
+		"12,5":     12.5,
+		"1.234,50": 1234.5,
+		"1,234.50": 1234.5,
+	} {
+		parsed, err := parsePosNumber(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, expected, parsed, value)
+	}
+	_, err := parsePosNumber("two")
+	assert.Error(t, err)
+}
+
+func TestParsePosCsv(t *testing.T) {
+	settings := viewmodels.PosSettings{
+		Delimiter:      ";",
+		DateFormat:     "DD.MM.YYYY",
+		DateColumn:     "Datum",
+		ItemColumn:     "PLU",
+		NameColumn:     "Artikel",
+		QuantityColumn: "Menge",
+		RevenueColumn:  "Umsatz",
+	}
+	export := "\ufeffDatum;PLU;Artikel;Menge;Umsatz\n" +
+		"01.10.2026 23:59;101;Burger;3;34,50\n" +
+		"01.10.2026;102;Cola;2;\n" +
+		";;Summe;5;34,50\n"
+
+	lines, err := parsePosCsv(strings.NewReader(export), settings)
+	assert.NoError(t, err)
+	assert.Len(t, lines, 2)
+	assert.Equal(t, posLine{item: "101", name: "Burger", day: 1790812800, quantity: 3, revenue: utils.Ptr(34.5)}, lines[0])
+	assert.Nil(t, lines[1].revenue)
+
+	settings.QuantityColumn = "Anzahl"
+	_, err = parsePosCsv(strings.NewReader(export), settings)
+	assert.ErrorContains(t, err, `column "Anzahl" not found`)
+}
+
+func TestAggregatePosSales(t *testing.T) {
+	burger := int64(1)
+	lines := []posLine{
+		{item: "101", day: 2, quantity: 3, revenue: utils.Ptr(30.0)},
+		{item: "burger-xl", day: 2, quantity: 1, revenue: utils.Ptr(12.0)},
+		{item: "101", day: 1, quantity: 2},
+		{item: "999", day: 1, quantity: 4},
+		{item: "999", day: 2, quantity: 1},
+	}
+	sales, unmapped := aggregatePosSales(lines, map[string]*int64{
+		"101":       &burger,
+		"burger-xl": &burger,
+		"999":       nil,
+	})
+	assert.Equal(t, []string{"999"}, unmapped)
+	assert.Len(t, sales, 2)
+	assert.Equal(t, int64(1), sales[0].SoldOn)
+	assert.Equal(t, 2.0, sales[0].Quantity)
+	assert.Nil(t, sales[0].Revenue)
+	assert.Equal(t, 4.0, sales[1].Quantity)
+	assert.Equal(t, 42.0, *sales[1].Revenue)
+	assert.Equal(t, "pos", sales[1].Source)
+}
@@ -152,7 +152,12 @@ func (pc *PriceCalcService) parseIngredientsWithPriceUnitRow(
 			target = ing
 		} else {
 			target = utils.AppendAndGetPtr(&out, viewmodels.IngredientWithPrices{
-				Ingredient: db.Ingredient{ID: ingredientRow.ID, Name: ingredientRow.Name},
+				Ingredient: db.Ingredient{
+					ID:           ingredientRow.ID,
+					Name:         ingredientRow.Name,
+					Abv:          ingredientRow.Abv,
+					DutyExcluded: ingredientRow.DutyExcluded,
+				},
 			})
 		}
 
@@ -169,6 +174,7 @@ func (pc *PriceCalcService) parseIngredientsWithPriceUnitRow(
 				Quantity:      *ingredientRow.Quantity,
 				UnitID:        *ingredientRow.UnitID,
 				BaseProductID: ingredientRow.BaseProductID,
+				Deposit:       utils.Deref(ingredientRow.Deposit),
 			})
 		} else if ingredientRow.PriceID != nil {
 			return nil, fmt.Errorf("missing fields in ingredient price row: %d", ingredientRow.ID)
@@ -222,7 +228,7 @@ func (pc *PriceCalcService) UpdateProductCost(
 	productID int64,
 ) (float64, error) {
 	visited := make(map[int64]bool)
-	cost, err := pc.calculateProductCost(productID, visited, ctx)
+	cost, err := pc.calculateProductCost(productID, visited, ctx, qtx)
 	if err != nil {
 		return 0, err
 	}
@@ -240,19 +246,26 @@ func (pc *PriceCalcService) UpdateProductCost(
 			continue
 		}
 
-		cost, err = pc.calculateProductCost(id, map[int64]bool{}, ctx)
+		var subCost float64
+		subCost, err = pc.calculateProductCost(id, map[int64]bool{}, ctx, qtx)
 		if err != nil {
 			return 0, err
 		}
 		_, err = qtx.InsertProductCost(ctx, db.InsertProductCostParams{
 			ProductID: id,
-			Cost:      cost,
+			Cost:      subCost,
 		})
 		if err != nil {
 			return 0, err
 		}
 	}
 
+	// bundles are costed from the cache of their components, so they have to follow
+	err = pc.updateBundleCosts(ctx, qtx, productID)
+	if err != nil {
+		return 0, err
+	}
+
 	return cost, nil
 }
 
@@ -260,31 +273,49 @@ func (pc *PriceCalcService) calculateProductCost(
 	productID int64,
 	visited map[int64]bool,
 	c context.Context,
+	qtx *db.Queries,
 ) (float64, error) {
 	if visited[productID] {
 		return 0, fmt.Errorf("circular dependency detected on product %d", productID)
 	}
 	visited[productID] = true
 
-	ingredientUsages, err := pc.queries.GetIngredientUsageForProductWithPrice(c, productID)
+	ingredientUsages, err := qtx.GetIngredientUsageForProductWithPrice(c, productID)
+	if err != nil {
+		return 0, err
+	}
+	dutyRate, err := settingFloat(c, qtx, settingSpiritsDutyRate)
 	if err != nil {
 		return 0, err
 	}
 	totalCost := 0.0
 	for _, ingredientUsage := range ingredientUsages {
 		if ingredientUsage.BaseProductID != nil {
-			subCost, err := pc.calculateProductCost(*ingredientUsage.BaseProductID, visited, c)
+			subCost, err := pc.calculateProductCost(*ingredientUsage.BaseProductID, visited, c, qtx)
 			if err != nil {
 				return 0, err
 			}
 			totalCost += subCost * ingredientUsage.Quantity
 		} else if ingredientUsage.Price != nil {
 			totalCost += *ingredientUsage.Price * ingredientUsage.Quantity
+			totalCost += ingredientDuty(ingredientUsage, dutyRate)
 		} else {
 			return 0, fmt.Errorf("no price found for ingredient %d", ingredientUsage.IngredientID)
 		}
 	}
 
+	components, err := qtx.GetBundleComponents(c, productID)
+	if err != nil {
+		return 0, err
+	}
+	for _, component := range components {
+		componentCost, err := pc.bundleComponentCost(c, qtx, component)
+		if err != nil {
+			return 0, err
+		}
+		totalCost += componentCost * component.Quantity
+	}
+
 	return totalCost, nil
 }
 
@@ -357,15 +388,26 @@ func (pc *PriceCalcService) NewIngredient(
 
 	qtx := pc.queries.WithTx(tx)
 
-	ingredient, err := qtx.InsertIngredient(ctx, params.Name)
+	err = validateAbv(params.Abv)
+	if err != nil {
+		return nil, err
+	}
+
+	ingredient, err := qtx.InsertIngredient(ctx, db.InsertIngredientParams{
+		Name:         params.Name,
+		Abv:          params.Abv,
+		DutyExcluded: params.DutyExcluded,
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	priceRow := db.GetIngredientsWithPriceUnitRow{
-		ID:      ingredient.ID,
-		Name:    ingredient.Name,
-		PriceID: nil,
+		ID:           ingredient.ID,
+		Name:         ingredient.Name,
+		Abv:          ingredient.Abv,
+		DutyExcluded: ingredient.DutyExcluded,
+		PriceID:      nil,
 	}
 	params.ID = ingredient.ID
 
@@ -374,6 +416,11 @@ func (pc *PriceCalcService) NewIngredient(
 		return nil, err
 	}
 
+	err = pc.setIngredientAllergens(ctx, qtx, ingredient.ID, params.Allergens)
+	if err != nil {
+		return nil, err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return nil, err
@@ -387,23 +434,34 @@ func (pc *PriceCalcService) NewIngredient(
 	return &ingredients[0], nil
 }
 
-func (pc *PriceCalcService) syncIngredientName(
+func (pc *PriceCalcService) syncIngredient(
 	ctx context.Context,
 	qtx *db.Queries,
 	row *db.GetIngredientsWithPriceUnitRow,
-	name string,
+	params UpdateIngredientParams,
 ) error {
-	if row.Name != name {
+	if row.Name != params.Name ||
+		!utils.PtrsEqual(row.Abv, params.Abv) ||
+		row.DutyExcluded != params.DutyExcluded {
+		err := validateAbv(params.Abv)
+		if err != nil {
+			return err
+		}
+
 		var ingredient db.Ingredient
-		ingredient, err := qtx.UpdateIngredient(ctx, db.UpdateIngredientParams{
-			ID:   row.ID,
-			Name: name,
+		ingredient, err = qtx.UpdateIngredient(ctx, db.UpdateIngredientParams{
+			ID:           row.ID,
+			Name:         params.Name,
+			Abv:          params.Abv,
+			DutyExcluded: params.DutyExcluded,
 		})
 		if err != nil {
 			return err
 		}
 
 		row.Name = ingredient.Name
+		row.Abv = ingredient.Abv
+		row.DutyExcluded = ingredient.DutyExcluded
 	}
 
 	return nil
@@ -437,10 +495,18 @@ func (pc *PriceCalcService) insertIngredientPrice(
 		return errors.New("either price or baseProductId must be set but not both")
 	}
 
+	if params.Deposit < 0 {
+		return errors.New("deposit must not be negative")
+	}
+
 	baseUnitQuantity := params.Quantity / unit.Factor
 	var baseUnitPrice *float64 = nil
 	if params.Price != nil {
-		baseUnitPrice = utils.Ptr(*params.Price / baseUnitQuantity)
+		// the deposit is refunded, so it is not part of the cost
+		if params.Deposit > *params.Price {
+			return errors.New("deposit must not exceed the price")
+		}
+		baseUnitPrice = utils.Ptr((*params.Price - params.Deposit) / baseUnitQuantity)
 	}
 
 	var ingredientPrice db.IngredientPrice
@@ -448,7 +514,8 @@ func (pc *PriceCalcService) insertIngredientPrice(
 		!utils.PtrsEqual(row.Price, baseUnitPrice) ||
 		!utils.PtrsEqual(row.BaseProductID, params.BaseProductID) ||
 		*row.Quantity != params.Quantity ||
-		*row.UnitID != params.UnitID {
+		*row.UnitID != params.UnitID ||
+		utils.Deref(row.Deposit) != params.Deposit {
 
 		pc.logger.Debug(
 			"update ingredient price",
@@ -468,6 +535,7 @@ func (pc *PriceCalcService) insertIngredientPrice(
 				BaseProductID: params.BaseProductID,
 				Quantity:      params.Quantity,
 				UnitID:        params.UnitID,
+				Deposit:       params.Deposit,
 			},
 		)
 		if err != nil {
@@ -480,6 +548,7 @@ func (pc *PriceCalcService) insertIngredientPrice(
 		row.Quantity = &ingredientPrice.Quantity
 		row.UnitID = &ingredientPrice.UnitID
 		row.BaseProductID = ingredientPrice.BaseProductID
+		row.Deposit = &ingredientPrice.Deposit
 	}
 
 	return nil
@@ -492,6 +561,11 @@ type UpdateIngredientParams struct {
 	Quantity      float64
 	UnitID        int64
 	BaseProductID *int64
+	// Deposit is the deposit per pack, it is included in Price
+	Deposit      float64
+	Abv          *float64
+	DutyExcluded bool
+	Allergens    []int64
 }
 
 func (pc *PriceCalcService) UpdateIngredientWithPrice(
@@ -523,7 +597,7 @@ func (pc *PriceCalcService) UpdateIngredientWithPrice(
 
 	ingredientWithPriceRow := ingredientWithPriceRows[0]
 
-	err = pc.syncIngredientName(ctx, qtx, &ingredientWithPriceRow, params.Name)
+	err = pc.syncIngredient(ctx, qtx, &ingredientWithPriceRow, params)
 	if err != nil {
 		return nil, err
 	}
@@ -533,6 +607,11 @@ func (pc *PriceCalcService) UpdateIngredientWithPrice(
 		return nil, err
 	}
 
+	err = pc.setIngredientAllergens(ctx, qtx, params.ID, params.Allergens)
+	if err != nil {
+		return nil, err
+	}
+
 	// find all products that use this ingredient
 	products, err := qtx.GetProductsFromIngredient(ctx, params.ID)
 	if err != nil {
@@ -597,14 +676,68 @@ func (pc *PriceCalcService) GetUnit(unitId int64) (*db.Unit, error) {
 
 func (pc *PriceCalcService) DeleteIngredient(ingredientId int64) error {
 	ctx := context.Background()
-	num, err := pc.queries.DeleteIngredient(ctx, ingredientId)
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	num, err := qtx.DeleteIngredient(ctx, ingredientId)
 	if err != nil {
 		return err
 	}
 	if num < 1 {
 		return ErrNoRowsAffected
 	}
-	return nil
+	// the handler refuses to delete packaging that is still in use, the rows
+	// are removed so no channel margin refers to a missing ingredient
+	err = qtx.DeleteIngredientChannelPackaging(ctx, ingredientId)
+	if err != nil {
+		return err
+	}
+	err = qtx.DeleteIngredientAllergens(ctx, ingredientId)
+	if err != nil {
+		return err
+	}
+	err = qtx.DeleteIngredientNutrition(ctx, ingredientId)
+	if err != nil {
+		return err
+	}
+	err = qtx.DeleteIngredientScenarioOverrides(ctx, ingredientId)
+	if err != nil {
+		return err
+	}
+	err = qtx.DeleteIngredientGoodsReceipts(ctx, ingredientId)
+	if err != nil {
+		return err
+	}
+	err = qtx.DeleteIngredientStock(ctx, ingredientId)
+	if err != nil {
+		return err
+	}
+	err = qtx.DeleteIngredientStocktakeCounts(ctx, ingredientId)
+	if err != nil {
+		return err
+	}
+	err = qtx.DeleteIngredientParLevel(ctx, ingredientId)
+	if err != nil {
+		return err
+	}
+	err = qtx.DeleteIngredientPurchaseOrderLines(ctx, ingredientId)
+	if err != nil {
+		return err
+	}
+	err = qtx.DeleteIngredientWasteStock(ctx, ingredientId)
+	if err != nil {
+		return err
+	}
+	err = qtx.DeleteIngredientWaste(ctx, &ingredientId)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (pc *PriceCalcService) GetProductsWithCost() ([]viewmodels.ProductWithCost, error) {
@@ -632,10 +765,22 @@ func (pc *PriceCalcService) GetProductsWithCost() ([]viewmodels.ProductWithCost,
 				CategoryID:    product.CategoryID,
 				Price:         product.Price,
 				Multiplicator: product.Multiplicator,
+				PrepMinutes:   product.PrepMinutes,
+				Deposit:       product.Deposit,
+				Servings:      product.Servings,
 			},
 			Cost: *product.Cost,
 		})
 	}
+
+	err = pc.addCostBreakdowns(ctx, out)
+	if err != nil {
+		return nil, err
+	}
+	err = pc.addAllergens(ctx, out)
+	if err != nil {
+		return nil, err
+	}
 	return out, nil
 }
 
@@ -666,17 +811,39 @@ func (pc *PriceCalcService) GetProductWithCost(
 			CategoryID:    product.CategoryID,
 			Price:         product.Price,
 			Multiplicator: product.Multiplicator,
+			PrepMinutes:   product.PrepMinutes,
+			Deposit:       product.Deposit,
+			Servings:      product.Servings,
 		},
 		Cost: product.Cost,
 	}
-	return &productWithCost, nil
+
+	products := []viewmodels.ProductWithCost{productWithCost}
+	err = pc.addCostBreakdowns(ctx, products)
+	if err != nil {
+		return nil, err
+	}
+	err = pc.addAllergens(ctx, products)
+	if err != nil {
+		return nil, err
+	}
+	return &products[0], nil
 }
 
 func (pc *PriceCalcService) UpdateProduct(
 	productId, categoryId int64,
-	price, multiplicator float64,
+	price, multiplicator, prepMinutes, deposit, servings float64,
 	name string,
 ) (*db.Product, error) {
+	if prepMinutes < 0 {
+		return nil, errors.New("preparation time must not be negative")
+	}
+	if deposit < 0 {
+		return nil, errors.New("deposit must not be negative")
+	}
+	if servings <= 0 {
+		return nil, errors.New("servings must be greater than 0")
+	}
 	ctx := context.Background()
 	product, err := pc.queries.UpdateProduct(ctx, db.UpdateProductParams{
 		ID:            productId,
@@ -684,6 +851,9 @@ func (pc *PriceCalcService) UpdateProduct(
 		Price:         price,
 		Name:          name,
 		Multiplicator: multiplicator,
+		PrepMinutes:   prepMinutes,
+		Deposit:       deposit,
+		Servings:      servings,
 	})
 	if err != nil {
 		return nil, err
@@ -692,15 +862,105 @@ func (pc *PriceCalcService) UpdateProduct(
 	return &product, nil
 }
 
+// DeleteProduct deletes the product with everything that belongs to it, the
+// foreign keys are not enforced
 func (pc *PriceCalcService) DeleteProduct(productId int64) error {
 	ctx := context.Background()
-	num, err := pc.queries.DeleteProduct(ctx, productId)
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	num, err := qtx.DeleteProduct(ctx, productId)
 	if err != nil {
-		return nil
+		return err
 	}
 	if num < 1 {
 		return ErrNoRowsAffected
 	}
+	err = qtx.DeleteProductIngredientUsage(ctx, productId)
+	if err != nil {
+		return err
+	}
+	err = qtx.DeleteProductCost(ctx, productId)
+	if err != nil {
+		return err
+	}
+	// the bundles the product was a component of are cheaper without it
+	bundles, err := qtx.GetBundlesFromProduct(ctx, productId)
+	if err != nil {
+		return err
+	}
+	err = qtx.DeleteProductBundleComponents(ctx, productId)
+	if err != nil {
+		return err
+	}
+	for _, bundle := range bundles {
+		_, err = pc.UpdateProductCost(ctx, qtx, bundle.ID)
+		if err != nil {
+			return err
+		}
+	}
+	err = qtx.DeleteProductChannelPrices(ctx, productId)
+	if err != nil {
+		return err
+	}
+	err = qtx.DeleteProductChannelPackaging(ctx, productId)
+	if err != nil {
+		return err
+	}
+	err = qtx.DeleteProductRecipe(ctx, productId)
+	if err != nil {
+		return err
+	}
+	err = qtx.DeleteProductRecipeSteps(ctx, productId)
+	if err != nil {
+		return err
+	}
+	photos, err := qtx.GetProductPhotos(ctx, productId)
+	if err != nil {
+		return err
+	}
+	err = qtx.DeleteProductPhotos(ctx, productId)
+	if err != nil {
+		return err
+	}
+	err = qtx.DeleteProductSales(ctx, productId)
+	if err != nil {
+		return err
+	}
+	// the items are imported again once they are mapped to another product
+	err = qtx.UnmapProductPosItems(ctx, &productId)
+	if err != nil {
+		return err
+	}
+	// the stock the waste took stays taken, it is gone either way
+	err = qtx.DeleteProductWasteStock(ctx, &productId)
+	if err != nil {
+		return err
+	}
+	err = qtx.DeleteProductWaste(ctx, &productId)
+	if err != nil {
+		return err
+	}
+	err = qtx.DeleteProductComps(ctx, productId)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	// the files are only removed once the rows are gone for good
+	for _, photo := range photos {
+		err = removeProductPhotoFile(photo.FileName)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -735,20 +995,40 @@ func (pc *PriceCalcService) GetCategories() ([]db.Category, error) {
 	return categories, nil
 }
 
-func (pc *PriceCalcService) PutCategory(name string, vat int64) (*db.Category, error) {
+func (pc *PriceCalcService) PutCategory(
+	name string,
+	vat int64,
+	dineInVatRateId, takeawayVatRateId *int64,
+) (*db.Category, error) {
 	ctx := context.Background()
-	category, err := pc.queries.PutCategory(ctx, db.PutCategoryParams{Name: name, Vat: vat})
+	category, err := pc.queries.PutCategory(ctx, db.PutCategoryParams{
+		Name:              name,
+		Vat:               vat,
+		DineInVatRateID:   dineInVatRateId,
+		TakeawayVatRateID: takeawayVatRateId,
+	})
 	if err != nil {
 		return nil, err
 	}
 	return &category, nil
 }
 
-func (pc *PriceCalcService) UpdateCategory(id int64, name string, vat int64) (*db.Category, error) {
+func (pc *PriceCalcService) UpdateCategory(
+	id int64,
+	name string,
+	vat int64,
+	dineInVatRateId, takeawayVatRateId *int64,
+) (*db.Category, error) {
 	ctx := context.Background()
 	category, err := pc.queries.UpdateCategory(
 		ctx,
-		db.UpdateCategoryParams{ID: id, Name: name, Vat: vat},
+		db.UpdateCategoryParams{
+			ID:                id,
+			Name:              name,
+			Vat:               vat,
+			DineInVatRateID:   dineInVatRateId,
+			TakeawayVatRateID: takeawayVatRateId,
+		},
 	)
 	if err != nil {
 		return nil, err
@@ -481,6 +481,57 @@ func TestSyncIngredientPrice(t *testing.T) {
 				Factor: 1,
 			},
 		},
+		{
+			name:                    "Crate with deposit, deposit is not part of the price",
+			expectError:             false,
+			expectPriceInsertCalled: true,
+			row: db.GetIngredientsWithPriceUnitRow{
+				ID:            1,
+				Name:          "Beer",
+				PriceID:       utils.Ptr(int64(101)),
+				TimeStamp:     nil,
+				Price:         utils.Ptr(1.5),
+				Quantity:      utils.Ptr(10.0),
+				UnitID:        utils.Ptr(int64(1)),
+				BaseProductID: nil,
+			},
+			params: UpdateIngredientParams{
+				ID:            1,
+				Name:          "Beer",
+				Price:         utils.Ptr(18.42),
+				Quantity:      10,
+				UnitID:        1,
+				BaseProductID: nil,
+				Deposit:       3.42,
+			},
+			unit: db.Unit{
+				ID:     1,
+				Name:   "unit",
+				Factor: 1,
+			},
+		},
+		{
+			name:                    "Deposit higher than price, should error",
+			expectError:             true,
+			expectPriceInsertCalled: false,
+			row: db.GetIngredientsWithPriceUnitRow{
+				ID:   1,
+				Name: "Beer",
+			},
+			params: UpdateIngredientParams{
+				ID:       1,
+				Name:     "Beer",
+				Price:    utils.Ptr(1.0),
+				Quantity: 1,
+				UnitID:   1,
+				Deposit:  2,
+			},
+			unit: db.Unit{
+				ID:     1,
+				Name:   "unit",
+				Factor: 1,
+			},
+		},
 		{
 			name:                    "price is nil, baseproduct unchanged, quantity changed, should insert",
 			expectError:             false,
@@ -551,7 +602,7 @@ func TestSyncIngredientPrice(t *testing.T) {
 					tc.row.Price,
 					"if tc.params.Price is not nil, tc.row.Price should not be nil",
 				) {
-					expectedPrice := (*tc.params.Price * tc.unit.Factor) / tc.params.Quantity
+					expectedPrice := ((*tc.params.Price - tc.params.Deposit) * tc.unit.Factor) / tc.params.Quantity
 					assert.InDelta(
 						t,
 						expectedPrice,
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/mike-jl/price_calc/db"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+// priceListEntry splits the price and the deposit of a product into net and VAT.
+// The deposit is taxed at the rate of the product it is charged on.
+func priceListEntry(product db.Product, category viewmodels.CategoryWithVat) viewmodels.PriceListEntry {
+	vat := category.DineInVat
+	entry := viewmodels.PriceListEntry{
+		ProductID: product.ID,
+		Name:      product.Name,
+		Category:  category.Name,
+		Vat:       vat,
+		Price:     product.Price,
+		NetPrice:  product.Price / (1 + vat/100),
+		Deposit:   product.Deposit,
+		Total:     product.Price + product.Deposit,
+	}
+	entry.DepositNet = entry.Deposit / (1 + vat/100)
+	entry.TotalVat = entry.Total - entry.NetPrice - entry.DepositNet
+	return entry
+}
+
+func (pc *PriceCalcService) GetPriceList(
+	ctx context.Context,
+	at time.Time,
+) ([]viewmodels.PriceListEntry, error) {
+	products, err := pc.GetProductsWithCost()
+	if err != nil {
+		return nil, err
+	}
+	categories, err := pc.GetCategoriesWithVat(ctx, at)
+	if err != nil {
+		return nil, err
+	}
+
+	categoriesMap := make(map[int64]viewmodels.CategoryWithVat, len(categories))
+	for _, category := range categories {
+		categoriesMap[category.ID] = category
+	}
+
+	out := make([]viewmodels.PriceListEntry, len(products))
+	for i, product := range products {
+		out[i] = priceListEntry(product.Product, categoriesMap[product.Product.CategoryID])
+		out[i].Allergens = product.Allergens
+	}
+	return out, nil
+}
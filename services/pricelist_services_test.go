@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/mike-jl/price_calc/db"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceListEntry(t *testing.T) {
+	drinks := viewmodels.CategoryWithVat{
+		Category:  db.Category{ID: 2, Name: "Drinks", Vat: 19},
+		DineInVat: 19,
+	}
+
+	tests := []struct {
+		name               string
+		product            db.Product
+		expectedNet        float64
+		expectedDepositNet float64
+		expectedTotal      float64
+		expectedTotalVat   float64
+	}{
+		{
+			name:               "Bottled beer with deposit",
+			product:            db.Product{ID: 1, Name: "Beer", CategoryID: 2, Price: 3.57, Deposit: 0.238},
+			expectedNet:        3,
+			expectedDepositNet: 0.2,
+			expectedTotal:      3.808,
+			expectedTotalVat:   0.608,
+		},
+		{
+			name:               "No deposit",
+			product:            db.Product{ID: 2, Name: "Wine", CategoryID: 2, Price: 5.95},
+			expectedNet:        5,
+			expectedDepositNet: 0,
+			expectedTotal:      5.95,
+			expectedTotalVat:   0.95,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := priceListEntry(tt.product, drinks)
+			assert.Equal(t, "Drinks", entry.Category)
+			assert.InDelta(t, tt.expectedNet, entry.NetPrice, 0.0001)
+			assert.InDelta(t, tt.expectedDepositNet, entry.DepositNet, 0.0001)
+			assert.InDelta(t, tt.expectedTotal, entry.Total, 0.0001)
+			assert.InDelta(t, tt.expectedTotalVat, entry.TotalVat, 0.0001)
+		})
+	}
+}
@@ -0,0 +1,451 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mike-jl/price_calc/db"
+	"github.com/mike-jl/price_calc/internal/utils"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+var errPurchaseOrderReceived = errors.New("purchase order is already received")
+
+type ParLevelParams struct {
+	IngredientID int64
+	Supplier     string
+	Par          float64
+	ReorderPoint float64
+	// PackSize is what the supplier sells, 0 orders the exact quantity
+	PackSize float64
+	UnitID   int64
+}
+
+func validateParLevel(params ParLevelParams) error {
+	if params.Par <= 0 {
+		return errors.New("par must be greater than 0")
+	}
+	if params.ReorderPoint < 0 {
+		return errors.New("reorder point must not be negative")
+	}
+	if params.ReorderPoint > params.Par {
+		return errors.New("reorder point must not exceed par")
+	}
+	if params.PackSize < 0 {
+		return errors.New("pack size must not be negative")
+	}
+	return nil
+}
+
+// orderQuantity returns how much of an ingredient to order in base units and
+// in how many packs. The stock expected after the forecast consumption,
+// including what is already on order, is filled up to par once it falls to
+// the reorder point.
+func orderQuantity(
+	stock float64,
+	forecast float64,
+	onOrder float64,
+	par float64,
+	reorderPoint float64,
+	packSize float64,
+) (float64, float64) {
+	projected := stock - forecast + onOrder
+	if projected > reorderPoint {
+		return 0, 0
+	}
+	need := par - projected
+	if packSize <= 0 {
+		return need, 0
+	}
+	// tolerate rounding errors so an exact number of packs is not rounded up
+	packs := math.Ceil(need/packSize - 1e-9)
+	return packs * packSize, packs
+}
+
+// currentStock returns the stock of every ingredient kept in inventory in
+// base units. The stock levels are set by the last count and receipts but
+// sales and comps do not lower them, so what they should have used since the
+// last count of an ingredient is taken off like stocktakeVariances does.
+func (pc *PriceCalcService) currentStock(ctx context.Context, now time.Time) (map[int64]float64, error) {
+	levels, err := pc.queries.GetStockLevels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counted, err := pc.queries.GetLastCountedAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lastCount := make(map[int64]int64, len(counted))
+	for _, row := range counted {
+		lastCount[row.IngredientID] = row.CountedAt
+	}
+
+	// consumption of the sales and comps after the last count by its date
+	consumed := map[int64]map[int64]float64{}
+	out := make(map[int64]float64, len(levels))
+	for _, level := range levels {
+		since := lastCount[level.IngredientID]
+		if _, ok := consumed[since]; !ok {
+			_, _, consumed[since], err = pc.periodConsumption(ctx, since+1, saleDay(now))
+			if err != nil {
+				return nil, err
+			}
+		}
+		// there is no less than nothing on hand
+		out[level.IngredientID] = max(0, level.Quantity-consumed[since][level.IngredientID])
+	}
+	return out, nil
+}
+
+func (pc *PriceCalcService) PutParLevel(ctx context.Context, params ParLevelParams) error {
+	err := validateParLevel(params)
+	if err != nil {
+		return err
+	}
+	rows, err := pc.queries.GetIngredientsWithPriceUnit(ctx, db.GetIngredientsWithPriceUnitParams{
+		IngredientID: params.IngredientID,
+		PriceLimit:   1,
+	})
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("ingredient with id %d not found", params.IngredientID)
+	}
+	unit, err := pc.queries.GetUnit(ctx, params.UnitID)
+	if err != nil {
+		return err
+	}
+	err = checkStockUnit(ctx, pc.queries, rows[0], unit)
+	if err != nil {
+		return err
+	}
+
+	_, err = pc.queries.PutParLevel(ctx, db.PutParLevelParams{
+		IngredientID: params.IngredientID,
+		Supplier:     strings.TrimSpace(params.Supplier),
+		Par:          params.Par,
+		ReorderPoint: params.ReorderPoint,
+		PackSize:     params.PackSize,
+		UnitID:       params.UnitID,
+	})
+	return err
+}
+
+func (pc *PriceCalcService) DeleteParLevel(ctx context.Context, parLevelId int64) error {
+	num, err := pc.queries.DeleteParLevel(ctx, parLevelId)
+	if err != nil {
+		return err
+	}
+	if num < 1 {
+		return ErrNoRowsAffected
+	}
+	return nil
+}
+
+// PurchaseOrderParams select what the stock is compared with par. With
+// Forecast the consumption of the sales and comps of the last Days days is
+// expected to repeat until the delivery.
+type PurchaseOrderParams struct {
+	Forecast bool
+	Days     int
+}
+
+// GeneratePurchaseOrders creates one purchase order per supplier for every
+// ingredient that falls to its reorder point and returns how many were
+// created
+func (pc *PriceCalcService) GeneratePurchaseOrders(
+	ctx context.Context,
+	params PurchaseOrderParams,
+	now time.Time,
+) (int, error) {
+	stock, err := pc.currentStock(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	forecast := map[int64]float64{}
+	if params.Forecast {
+		if params.Days < 1 {
+			return 0, errors.New("days must be at least 1")
+		}
+		_, _, forecast, err = pc.periodConsumption(
+			ctx,
+			saleDay(now.AddDate(0, 0, 1-params.Days)),
+			saleDay(now),
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	parLevels, err := qtx.GetParLevels(ctx)
+	if err != nil {
+		return 0, err
+	}
+	open, err := qtx.GetOpenPurchaseOrderQuantities(ctx)
+	if err != nil {
+		return 0, err
+	}
+	onOrder := make(map[int64]float64, len(open))
+	for _, line := range open {
+		onOrder[line.IngredientID] = line.Quantity
+	}
+
+	// the par levels are sorted by supplier
+	orders := 0
+	var order *db.PurchaseOrder
+	for _, parLevel := range parLevels {
+		quantity, packs := orderQuantity(
+			stock[parLevel.IngredientID],
+			forecast[parLevel.IngredientID],
+			onOrder[parLevel.IngredientID],
+			parLevel.Par/parLevel.Factor,
+			parLevel.ReorderPoint/parLevel.Factor,
+			parLevel.PackSize/parLevel.Factor,
+		)
+		if quantity <= 0 {
+			continue
+		}
+		if order == nil || order.Supplier != parLevel.Supplier {
+			inserted, err := qtx.InsertPurchaseOrder(ctx, db.InsertPurchaseOrderParams{
+				Supplier:  parLevel.Supplier,
+				CreatedAt: now.Unix(),
+			})
+			if err != nil {
+				return 0, err
+			}
+			order = &inserted
+			orders++
+		}
+		_, err = qtx.InsertPurchaseOrderLine(ctx, db.InsertPurchaseOrderLineParams{
+			PurchaseOrderID: order.ID,
+			IngredientID:    parLevel.IngredientID,
+			Quantity:        quantity * parLevel.Factor,
+			UnitID:          parLevel.UnitID,
+			Packs:           packs,
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return orders, tx.Commit()
+}
+
+// DeletePurchaseOrder deletes an order that was not received yet
+func (pc *PriceCalcService) DeletePurchaseOrder(ctx context.Context, orderId int64) error {
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	order, err := qtx.GetPurchaseOrder(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	if order.ReceivedAt != nil {
+		return errPurchaseOrderReceived
+	}
+	err = qtx.DeletePurchaseOrderLines(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	num, err := qtx.DeletePurchaseOrder(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	if num < 1 {
+		return ErrNoRowsAffected
+	}
+	return tx.Commit()
+}
+
+// PurchaseOrderReceipt is what was delivered for a line of a purchase order,
+// Price is what was paid for Quantity including Deposit
+type PurchaseOrderReceipt struct {
+	LineID   int64
+	Quantity float64
+	Price    float64
+	Deposit  float64
+}
+
+// ReceivePurchaseOrder books a goods receipt for every delivered line in the
+// unit it was ordered in. Lines without a quantity were not delivered.
+func (pc *PriceCalcService) ReceivePurchaseOrder(
+	ctx context.Context,
+	orderId int64,
+	receipts []PurchaseOrderReceipt,
+	receivedAt time.Time,
+) error {
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	order, err := qtx.GetPurchaseOrder(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	num, err := qtx.ReceivePurchaseOrder(ctx, db.ReceivePurchaseOrderParams{
+		ReceivedAt: utils.Ptr(receivedAt.Unix()),
+		ID:         orderId,
+	})
+	if err != nil {
+		return err
+	}
+	if num < 1 {
+		return errPurchaseOrderReceived
+	}
+	lines, err := qtx.GetPurchaseOrderLines(ctx, orderId)
+	if err != nil {
+		return err
+	}
+
+	for _, receipt := range receipts {
+		if receipt.Quantity == 0 {
+			continue
+		}
+		index := slices.IndexFunc(lines, func(line db.GetPurchaseOrderLinesRow) bool {
+			return line.ID == receipt.LineID
+		})
+		if index < 0 {
+			return fmt.Errorf("line %d is not part of purchase order %d", receipt.LineID, orderId)
+		}
+		err = pc.receiveGoods(ctx, qtx, GoodsReceiptParams{
+			IngredientID: lines[index].IngredientID,
+			Quantity:     receipt.Quantity,
+			UnitID:       lines[index].UnitID,
+			Price:        receipt.Price,
+			Deposit:      receipt.Deposit,
+			Supplier:     order.Supplier,
+			ReceivedAt:   receivedAt,
+		}, &orderId)
+		if err != nil {
+			return fmt.Errorf("%s: %w", lines[index].Name, err)
+		}
+	}
+
+	err = pc.updateAllProductCosts(ctx, qtx)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (pc *PriceCalcService) GetPurchasing(ctx context.Context) (*viewmodels.PurchasingViewModel, error) {
+	parLevels, err := pc.queries.GetParLevels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stock, err := pc.currentStock(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	open, err := pc.queries.GetOpenPurchaseOrderQuantities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := pc.queries.GetPurchaseOrders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ingredients, err := pc.getPricedIngredients(ctx)
+	if err != nil {
+		return nil, err
+	}
+	units, err := pc.GetUnits(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := viewmodels.PurchasingViewModel{
+		ParLevels:   make([]viewmodels.ParLevel, 0, len(parLevels)),
+		Orders:      orders,
+		Ingredients: make(map[int64]string, len(ingredients)),
+		Units:       units,
+	}
+	onOrder := make(map[int64]float64, len(open))
+	for _, line := range open {
+		onOrder[line.IngredientID] = line.Quantity
+	}
+	for _, parLevel := range parLevels {
+		row := viewmodels.ParLevel{
+			ID:           parLevel.ID,
+			IngredientID: parLevel.IngredientID,
+			Name:         parLevel.Name,
+			Supplier:     parLevel.Supplier,
+			Par:          parLevel.Par,
+			ReorderPoint: parLevel.ReorderPoint,
+			PackSize:     parLevel.PackSize,
+			Unit:         parLevel.UnitName,
+			Stock:        stock[parLevel.IngredientID] * parLevel.Factor,
+			OnOrder:      onOrder[parLevel.IngredientID] * parLevel.Factor,
+		}
+		// what is already ordered is counted like when orders are generated
+		row.Reorder = row.Stock+row.OnOrder <= row.ReorderPoint
+		out.ParLevels = append(out.ParLevels, row)
+	}
+	for id, ingredient := range ingredients {
+		out.Ingredients[id] = ingredient.Ingredient.Name
+	}
+	return &out, nil
+}
+
+// GetPurchaseOrder returns an order with the cost its lines are expected to
+// have at the current prices
+func (pc *PriceCalcService) GetPurchaseOrder(
+	ctx context.Context,
+	orderId int64,
+) (*viewmodels.PurchaseOrderViewModel, error) {
+	order, err := pc.queries.GetPurchaseOrder(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := pc.queries.GetPurchaseOrderLines(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+	ingredients, err := pc.getPricedIngredients(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := viewmodels.PurchaseOrderViewModel{
+		Order: order,
+		Lines: make([]viewmodels.PurchaseOrderLine, 0, len(lines)),
+	}
+	for _, line := range lines {
+		row := viewmodels.PurchaseOrderLine{
+			ID:           line.ID,
+			IngredientID: line.IngredientID,
+			Name:         line.Name,
+			Quantity:     line.Quantity,
+			Unit:         line.UnitName,
+			Packs:        line.Packs,
+		}
+		if ingredient, ok := ingredients[line.IngredientID]; ok {
+			row.ExpectedCost = line.Quantity / line.Factor * utils.Deref(ingredient.Prices[0].Price)
+		}
+		out.ExpectedCost += row.ExpectedCost
+		out.Lines = append(out.Lines, row)
+	}
+	return &out, nil
+}
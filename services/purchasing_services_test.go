@@ -0,0 +1,38 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderQuantity(t *testing.T) {
+	tests := []struct {
+		name                  string
+		stock, forecast, open float64
+		par, reorder, pack    float64
+		quantity, packs       float64
+	}{
+		{"above reorder point", 6, 0, 0, 10, 5, 0, 0, 0},
+		{"at reorder point", 5, 0, 0, 10, 5, 0, 5, 0},
+		{"rounded up to packs", 2, 0, 0, 10, 5, 3, 9, 3},
+		{"exact packs", 4, 0, 0, 10, 5, 0.2, 6, 30},
+		{"forecast", 8, 4, 0, 10, 5, 2.5, 7.5, 3},
+		{"already on order", 2, 0, 3, 10, 5, 0, 5, 0},
+		{"on order covers it", 2, 0, 6, 10, 5, 0, 0, 0},
+		{"forecast below zero", 1, 3, 0, 10, 0, 0, 12, 0},
+	}
+	for _, test := range tests {
+		quantity, packs := orderQuantity(test.stock, test.forecast, test.open, test.par, test.reorder, test.pack)
+		assert.InDelta(t, test.quantity, quantity, 1e-9, test.name)
+		assert.InDelta(t, test.packs, packs, 1e-9, test.name)
+	}
+}
+
+func TestValidateParLevel(t *testing.T) {
+	assert.NoError(t, validateParLevel(ParLevelParams{Par: 10, ReorderPoint: 5, PackSize: 2}))
+	assert.Error(t, validateParLevel(ParLevelParams{Par: 0}))
+	assert.Error(t, validateParLevel(ParLevelParams{Par: 10, ReorderPoint: 11}))
+	assert.Error(t, validateParLevel(ParLevelParams{Par: 10, ReorderPoint: -1}))
+	assert.Error(t, validateParLevel(ParLevelParams{Par: 10, PackSize: -1}))
+}
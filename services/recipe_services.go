@@ -0,0 +1,322 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mike-jl/price_calc/db"
+	"github.com/mike-jl/price_calc/internal/utils"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+// photos are stored next to the database so they survive a redeploy
+const photoDir = "db/photos"
+
+const maxPhotoSize = 10 << 20
+
+var photoExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// photoExtension sniffs the content type of an upload and returns the file
+// extension to store it with.
+func photoExtension(head []byte) (string, string, error) {
+	contentType := http.DetectContentType(head)
+	extension, ok := photoExtensions[contentType]
+	if !ok {
+		return "", "", fmt.Errorf("unsupported image type %s", contentType)
+	}
+	return contentType, extension, nil
+}
+
+// moveStep moves a step by offset and numbers all steps from 1. It returns
+// false if the step is not found or can not be moved that far.
+func moveStep(steps []db.RecipeStep, stepId int64, offset int) ([]db.RecipeStep, bool) {
+	from := -1
+	for i, step := range steps {
+		if step.ID == stepId {
+			from = i
+			break
+		}
+	}
+	to := from + offset
+	if from < 0 || to < 0 || to >= len(steps) {
+		return steps, false
+	}
+
+	out := slices.Delete(slices.Clone(steps), from, from+1)
+	out = slices.Insert(out, to, steps[from])
+	for i := range out {
+		out[i].Position = int64(i + 1)
+	}
+	return out, true
+}
+
+func (pc *PriceCalcService) GetRecipe(
+	ctx context.Context,
+	productId int64,
+) (*viewmodels.RecipeViewModel, error) {
+	product, err := pc.GetProductWithCost(productId)
+	if err != nil {
+		return nil, err
+	}
+
+	recipe, err := pc.queries.GetProductRecipe(ctx, productId)
+	if err == sql.ErrNoRows {
+		recipe = db.ProductRecipe{ProductID: productId}
+	} else if err != nil {
+		return nil, err
+	}
+
+	steps, err := pc.queries.GetRecipeSteps(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+	photos, err := pc.queries.GetProductPhotos(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	units, err := pc.GetUnitsMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	usages, err := pc.queries.GetIngredientUsageForProductWithPrice(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+	ingredients := make([]viewmodels.RecipeIngredient, 0, len(usages))
+	for _, usage := range usages {
+		unit := units[usage.UnitID]
+		ingredients = append(ingredients, viewmodels.RecipeIngredient{
+			Name:     utils.Deref(usage.Name),
+			Quantity: usage.Quantity * unit.Factor,
+			Unit:     unit.Name,
+		})
+	}
+
+	return &viewmodels.RecipeViewModel{
+		ProductID:   productId,
+		ProductName: product.Product.Name,
+		Recipe:      recipe,
+		Steps:       steps,
+		Photos:      photos,
+		Ingredients: ingredients,
+	}, nil
+}
+
+func (pc *PriceCalcService) UpdateRecipe(
+	ctx context.Context,
+	productId int64,
+	notes, glassware, garnish string,
+) error {
+	_, err := pc.queries.GetProductWithCost(ctx, productId)
+	if err != nil {
+		return err
+	}
+	_, err = pc.queries.PutProductRecipe(ctx, db.PutProductRecipeParams{
+		ProductID: productId,
+		Notes:     strings.TrimSpace(notes),
+		Glassware: strings.TrimSpace(glassware),
+		Garnish:   strings.TrimSpace(garnish),
+	})
+	return err
+}
+
+func (pc *PriceCalcService) AddRecipeStep(
+	ctx context.Context,
+	productId int64,
+	text string,
+) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return errors.New("step is empty")
+	}
+	_, err := pc.queries.GetProductWithCost(ctx, productId)
+	if err != nil {
+		return err
+	}
+	_, err = pc.queries.InsertRecipeStep(ctx, db.InsertRecipeStepParams{
+		ProductID: productId,
+		Text:      text,
+	})
+	return err
+}
+
+// UpdateRecipeStep returns the id of the product the step belongs to
+func (pc *PriceCalcService) UpdateRecipeStep(
+	ctx context.Context,
+	stepId int64,
+	text string,
+) (int64, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, errors.New("step is empty")
+	}
+	step, err := pc.queries.UpdateRecipeStep(ctx, db.UpdateRecipeStepParams{
+		ID:   stepId,
+		Text: text,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return step.ProductID, nil
+}
+
+// MoveRecipeStep returns the id of the product the step belongs to
+func (pc *PriceCalcService) MoveRecipeStep(
+	ctx context.Context,
+	stepId int64,
+	offset int,
+) (int64, error) {
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	step, err := qtx.GetRecipeStep(ctx, stepId)
+	if err != nil {
+		return 0, err
+	}
+	steps, err := qtx.GetRecipeSteps(ctx, step.ProductID)
+	if err != nil {
+		return 0, err
+	}
+
+	steps, ok := moveStep(steps, stepId, offset)
+	if !ok {
+		return step.ProductID, tx.Commit()
+	}
+	for _, step := range steps {
+		err = qtx.UpdateRecipeStepPosition(ctx, db.UpdateRecipeStepPositionParams{
+			ID:       step.ID,
+			Position: step.Position,
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return step.ProductID, tx.Commit()
+}
+
+// DeleteRecipeStep returns the id of the product the step belonged to
+func (pc *PriceCalcService) DeleteRecipeStep(ctx context.Context, stepId int64) (int64, error) {
+	step, err := pc.queries.GetRecipeStep(ctx, stepId)
+	if err != nil {
+		return 0, err
+	}
+	num, err := pc.queries.DeleteRecipeStep(ctx, stepId)
+	if err != nil {
+		return 0, err
+	}
+	if num < 1 {
+		return 0, ErrNoRowsAffected
+	}
+	return step.ProductID, nil
+}
+
+func (pc *PriceCalcService) AddProductPhoto(
+	ctx context.Context,
+	productId int64,
+	src io.Reader,
+) error {
+	_, err := pc.queries.GetProductWithCost(ctx, productId)
+	if err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(src, maxPhotoSize+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > maxPhotoSize {
+		return fmt.Errorf("photo is larger than %d MB", maxPhotoSize>>20)
+	}
+	contentType, extension, err := photoExtension(data)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(photoDir, 0o755)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	fileName := fmt.Sprintf("%d-%d%s", productId, now.UnixNano(), extension)
+	path := filepath.Join(photoDir, fileName)
+	err = os.WriteFile(path, data, 0o644)
+	if err != nil {
+		return err
+	}
+
+	_, err = pc.queries.InsertProductPhoto(ctx, db.InsertProductPhotoParams{
+		ProductID:   productId,
+		FileName:    fileName,
+		ContentType: contentType,
+		TimeStamp:   now.Unix(),
+	})
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+// GetProductPhotoPath returns the path of the stored file
+func (pc *PriceCalcService) GetProductPhotoPath(
+	ctx context.Context,
+	photoId int64,
+) (string, error) {
+	photo, err := pc.queries.GetProductPhoto(ctx, photoId)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(photoDir, photo.FileName), nil
+}
+
+// DeleteProductPhoto returns the id of the product the photo belonged to
+func (pc *PriceCalcService) DeleteProductPhoto(ctx context.Context, photoId int64) (int64, error) {
+	photo, err := pc.queries.GetProductPhoto(ctx, photoId)
+	if err != nil {
+		return 0, err
+	}
+	num, err := pc.queries.DeleteProductPhoto(ctx, photoId)
+	if err != nil {
+		return 0, err
+	}
+	if num < 1 {
+		return 0, ErrNoRowsAffected
+	}
+
+	err = removeProductPhotoFile(photo.FileName)
+	if err != nil {
+		return 0, err
+	}
+	return photo.ProductID, nil
+}
+
+// removeProductPhotoFile removes a stored photo, a file that is already gone
+// is not an error
+func removeProductPhotoFile(fileName string) error {
+	err := os.Remove(filepath.Join(photoDir, fileName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
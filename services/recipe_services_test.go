@@ -0,0 +1,59 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/mike-jl/price_calc/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoveStep(t *testing.T) {
+	steps := []db.RecipeStep{
+		{ID: 1, Position: 1, Text: "Chill the glass"},
+		{ID: 2, Position: 2, Text: "Add ice"},
+		{ID: 5, Position: 4, Text: "Pour gin"},
+	}
+
+	tests := []struct {
+		name        string
+		stepId      int64
+		offset      int
+		expectedIds []int64
+		expectedOk  bool
+	}{
+		{name: "Move down", stepId: 1, offset: 1, expectedIds: []int64{2, 1, 5}, expectedOk: true},
+		{name: "Move up", stepId: 5, offset: -1, expectedIds: []int64{1, 5, 2}, expectedOk: true},
+		{name: "First step up", stepId: 1, offset: -1, expectedIds: []int64{1, 2, 5}},
+		{name: "Last step down", stepId: 5, offset: 1, expectedIds: []int64{1, 2, 5}},
+		{name: "Unknown step", stepId: 3, offset: 1, expectedIds: []int64{1, 2, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, ok := moveStep(steps, tt.stepId, tt.offset)
+			assert.Equal(t, tt.expectedOk, ok)
+			ids := make([]int64, len(out))
+			for i, step := range out {
+				ids[i] = step.ID
+				if ok {
+					assert.Equal(t, int64(i+1), step.Position)
+				}
+			}
+			assert.Equal(t, tt.expectedIds, ids)
+		})
+	}
+
+	// the input is left untouched
+	assert.Equal(t, int64(4), steps[2].Position)
+}
+
+func TestPhotoExtension(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	contentType, extension, err := photoExtension(png)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+	assert.Equal(t, ".png", extension)
+
+	_, _, err = photoExtension([]byte("<html><body>not an image</body></html>"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mike-jl/price_calc/db"
+	"github.com/mike-jl/price_calc/internal/utils"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+// ReverseCostingParams describes the price a product should sell for. If
+// CostRatio is set it is used, otherwise Multiplicator. For an existing
+// product the missing values default to the product's own.
+type ReverseCostingParams struct {
+	GrossPrice    float64
+	CategoryID    int64
+	Multiplicator float64
+	// CostRatio is the share of the net price the cost basis may take, in percent
+	CostRatio float64
+	ProductID *int64
+}
+
+// targetBasis returns the net price and the highest cost on the configured
+// basis that still sells for grossPrice
+func targetBasis(grossPrice, vat, multiplicator, costRatio float64) (float64, float64, error) {
+	if grossPrice <= 0 {
+		return 0, 0, errors.New("target price must be greater than 0")
+	}
+	netPrice := grossPrice / (1 + vat/100)
+	switch {
+	case costRatio < 0 || costRatio > 100:
+		return 0, 0, errors.New("cost ratio must be between 0 and 100")
+	case costRatio > 0:
+		return netPrice, netPrice * costRatio / 100, nil
+	case multiplicator > 0:
+		return netPrice, netPrice / multiplicator, nil
+	}
+	return 0, 0, errors.New("multiplicator or cost ratio must be greater than 0")
+}
+
+// maxIngredientCost inverts calculateCostBreakdown. The basis grows linearly
+// with the ingredient cost, so two points are enough to solve for it. The
+// result is negative if labor and fixed overhead alone exceed the basis.
+func maxIngredientCost(basis float64, product db.Product, settings viewmodels.CostSettings) float64 {
+	withoutIngredients := calculateCostBreakdown(product, 0, settings).Basis
+	perIngredientCost := calculateCostBreakdown(product, 1, settings).Basis - withoutIngredients
+	return (basis - withoutIngredients) / perIngredientCost
+}
+
+// reverseCostingLines shows for every usage how much of it fits if the whole
+// recipe shrinks by the same factor, and the unit price at which the usage
+// alone would bring the recipe down to the budget
+func reverseCostingLines(
+	lines []recipeLine,
+	budget float64,
+	units []db.Unit,
+) (float64, []viewmodels.ReverseCostingLine) {
+	cost := 0.0
+	for _, line := range lines {
+		cost += line.cost
+	}
+	factor := 1.0
+	if cost > 0 {
+		factor = budget / cost
+	}
+	excess := cost - budget
+
+	out := make([]viewmodels.ReverseCostingLine, 0, len(lines))
+	for _, line := range lines {
+		row := viewmodels.ReverseCostingLine{
+			Name:        line.name,
+			Quantity:    line.quantity,
+			Unit:        "×",
+			MaxQuantity: line.quantity * factor,
+			PriceUnit:   "piece",
+			Cost:        line.cost,
+			BaseProduct: line.baseProduct,
+		}
+		if line.baseUnitId != 0 {
+			row.Quantity, row.Unit = displayQuantity(line.quantity, line.baseUnitId, units)
+			// keep the shrunk amount in the same unit to make it comparable
+			row.MaxQuantity = row.Quantity * factor
+			if unit, ok := utils.FirstPtr(units, func(unit db.Unit) bool {
+				return unit.ID == line.baseUnitId
+			}); ok {
+				row.PriceUnit = unit.Name
+			}
+		}
+		if line.quantity > 0 {
+			row.UnitPrice = (line.cost - line.duty) / line.quantity
+			if maxPrice := (line.cost - excess - line.duty) / line.quantity; maxPrice >= 0 {
+				row.MaxUnitPrice = &maxPrice
+			}
+		}
+		out = append(out, row)
+	}
+	return factor, out
+}
+
+func (pc *PriceCalcService) GetReverseCosting(
+	ctx context.Context,
+	params ReverseCostingParams,
+) (*viewmodels.ReverseCosting, error) {
+	now := time.Now()
+	categories, err := pc.GetCategoriesWithVat(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	products, err := pc.queries.GetProductNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := pc.GetCostSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := viewmodels.ReverseCosting{
+		GrossPrice:    params.GrossPrice,
+		CategoryID:    params.CategoryID,
+		Multiplicator: params.Multiplicator,
+		CostRatio:     params.CostRatio,
+		CostBasis:     settings.CostBasis,
+		ProductID:     params.ProductID,
+		Categories:    categories,
+		Products:      make(map[int64]string, len(products)),
+		Lines:         []viewmodels.ReverseCostingLine{},
+	}
+	for _, product := range products {
+		out.Products[product.ID] = product.Name
+	}
+
+	product := db.Product{CategoryID: params.CategoryID}
+	if params.ProductID != nil {
+		row, err := pc.queries.GetProductWithCost(ctx, *params.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		product = db.Product{
+			ID:            row.ID,
+			Name:          row.Name,
+			Price:         row.Price,
+			Multiplicator: row.Multiplicator,
+			CategoryID:    row.CategoryID,
+			PrepMinutes:   row.PrepMinutes,
+			Deposit:       row.Deposit,
+			Servings:      row.Servings,
+		}
+		if out.GrossPrice == 0 {
+			out.GrossPrice = product.Price
+		}
+		if out.CategoryID == 0 {
+			out.CategoryID = product.CategoryID
+		}
+		if out.Multiplicator == 0 && out.CostRatio == 0 {
+			out.Multiplicator = product.Multiplicator
+		}
+		// the overhead rules follow the category the product would be sold in
+		product.CategoryID = out.CategoryID
+	}
+	if out.GrossPrice == 0 && out.CategoryID == 0 {
+		return &out, nil
+	}
+
+	category, ok := utils.FirstPtr(categories, func(category viewmodels.CategoryWithVat) bool {
+		return category.ID == out.CategoryID
+	})
+	if !ok {
+		return nil, fmt.Errorf("category with id %d not found", out.CategoryID)
+	}
+	out.Vat = category.DineInVat
+
+	out.NetPrice, out.BasisBudget, err = targetBasis(
+		out.GrossPrice,
+		out.Vat,
+		out.Multiplicator,
+		out.CostRatio,
+	)
+	if err != nil {
+		return nil, err
+	}
+	out.IngredientBudget = maxIngredientCost(out.BasisBudget, product, *settings)
+	out.Calculated = true
+
+	if params.ProductID == nil {
+		return &out, nil
+	}
+	lines, err := pc.recipeLines(ctx, *params.ProductID, 1, settings.SpiritsDutyRate, map[int64]bool{})
+	if err != nil {
+		return nil, err
+	}
+	units, err := pc.GetUnits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out.ProductName = product.Name
+	out.ShrinkFactor, out.Lines = reverseCostingLines(lines, out.IngredientBudget, units)
+	for _, line := range out.Lines {
+		out.CurrentCost += line.Cost
+	}
+	return &out, nil
+}
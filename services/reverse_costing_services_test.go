@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/mike-jl/price_calc/db"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTargetBasis(t *testing.T) {
+	net, basis, err := targetBasis(11.9, 19, 4, 0)
+	assert.NoError(t, err)
+	assert.InDelta(t, 10, net, 0.0001)
+	assert.InDelta(t, 2.5, basis, 0.0001)
+
+	// the cost ratio wins over the multiplicator
+	_, basis, err = targetBasis(11.9, 19, 4, 30)
+	assert.NoError(t, err)
+	assert.InDelta(t, 3, basis, 0.0001)
+
+	_, _, err = targetBasis(11.9, 19, 0, 0)
+	assert.Error(t, err)
+	_, _, err = targetBasis(0, 19, 4, 0)
+	assert.Error(t, err)
+	_, _, err = targetBasis(11.9, 19, 4, 120)
+	assert.Error(t, err)
+}
+
+func TestMaxIngredientCost(t *testing.T) {
+	product := db.Product{CategoryID: 1, PrepMinutes: 5}
+	settings := viewmodels.CostSettings{
+		CostBasis: string(CostBasisIngredients),
+		LaborRate: 12,
+		OverheadRules: []db.OverheadRule{
+			{Kind: "fixed", Amount: 0.5},
+			{Kind: "percent", Amount: 25},
+		},
+	}
+	assert.InDelta(t, 2.5, maxIngredientCost(2.5, product, settings), 0.0001)
+
+	settings.CostBasis = string(CostBasisPrime)
+	assert.InDelta(t, 1.5, maxIngredientCost(2.5, product, settings), 0.0001)
+
+	settings.CostBasis = string(CostBasisFull)
+	budget := maxIngredientCost(2.5, product, settings)
+	assert.InDelta(t, 0.8, budget, 0.0001)
+	assert.InDelta(t, 2.5, calculateCostBreakdown(product, budget, settings).Basis, 0.0001)
+
+	assert.Less(t, maxIngredientCost(1, product, settings), 0.0)
+}
+
+func TestReverseCostingLines(t *testing.T) {
+	limeId := int64(5)
+	lines := []recipeLine{
+		{name: "Gin", quantity: 0.04, unitId: 3, baseUnitId: 1, ingredientId: 1, duty: 0.2, cost: 1.2},
+		{name: "Tonic", quantity: 0.2, unitId: 1, baseUnitId: 1, ingredientId: 2, cost: 0.8},
+		{name: "Lime", quantity: 1, productId: &limeId, cost: 0.1},
+	}
+
+	factor, rows := reverseCostingLines(lines, 1.5, testUnits)
+	assert.InDelta(t, 1.5/2.1, factor, 0.0001)
+	assert.Len(t, rows, 3)
+
+	assert.Equal(t, "cl", rows[0].Unit)
+	assert.InDelta(t, 4, rows[0].Quantity, 0.0001)
+	assert.InDelta(t, 4*1.5/2.1, rows[0].MaxQuantity, 0.0001)
+	assert.Equal(t, "l", rows[0].PriceUnit)
+	assert.InDelta(t, 25, rows[0].UnitPrice, 0.0001)
+	assert.InDelta(t, 10, *rows[0].MaxUnitPrice, 0.0001)
+
+	assert.InDelta(t, 1, *rows[1].MaxUnitPrice, 0.0001)
+
+	assert.Equal(t, "×", rows[2].Unit)
+	assert.Equal(t, "piece", rows[2].PriceUnit)
+	assert.Nil(t, rows[2].MaxUnitPrice)
+
+	// a recipe within budget can grow
+	factor, _ = reverseCostingLines(lines, 4.2, testUnits)
+	assert.InDelta(t, 2, factor, 0.0001)
+}
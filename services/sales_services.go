@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mike-jl/price_calc/db"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+// ManualSale is the count of a product entered for a day or shift, Revenue is
+// optional
+type ManualSale struct {
+	ProductID int64
+	Quantity  float64
+	Revenue   *float64
+}
+
+// saleDay returns the start of the day of t in UTC, which sales are stored by
+func saleDay(t time.Time) int64 {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix()
+}
+
+func validateManualSales(sales []ManualSale, products map[int64]string) error {
+	seen := map[int64]bool{}
+	for _, sale := range sales {
+		name, ok := products[sale.ProductID]
+		if !ok {
+			return fmt.Errorf("product with id %d not found", sale.ProductID)
+		}
+		if seen[sale.ProductID] {
+			return fmt.Errorf("%s is entered twice", name)
+		}
+		seen[sale.ProductID] = true
+		if sale.Quantity < 0 {
+			return fmt.Errorf("quantity of %s must not be negative", name)
+		}
+		if sale.Revenue != nil && *sale.Revenue < 0 {
+			return fmt.Errorf("revenue of %s must not be negative", name)
+		}
+		if sale.Quantity == 0 && sale.Revenue != nil && *sale.Revenue > 0 {
+			return fmt.Errorf("%s has revenue but no quantity", name)
+		}
+	}
+	return nil
+}
+
+// PutManualSales replaces the manually entered sales of a day and shift.
+// Products without a quantity are not stored.
+func (pc *PriceCalcService) PutManualSales(
+	ctx context.Context,
+	day time.Time,
+	shift string,
+	sales []ManualSale,
+) error {
+	shift = strings.TrimSpace(shift)
+	products, err := pc.GetProductNames(ctx)
+	if err != nil {
+		return err
+	}
+	err = validateManualSales(sales, products)
+	if err != nil {
+		return err
+	}
+
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	err = qtx.DeleteSalesOfDay(ctx, db.DeleteSalesOfDayParams{
+		SoldOn: saleDay(day),
+		Shift:  shift,
+		Source: string(SaleSourceManual),
+	})
+	if err != nil {
+		return err
+	}
+	for _, sale := range sales {
+		if sale.Quantity == 0 {
+			continue
+		}
+		_, err = qtx.InsertSale(ctx, db.InsertSaleParams{
+			ProductID: sale.ProductID,
+			SoldOn:    saleDay(day),
+			Shift:     shift,
+			Source:    string(SaleSourceManual),
+			Quantity:  sale.Quantity,
+			Revenue:   sale.Revenue,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetSalesEntry returns the manually entered sales of a day and shift and
+// the latest days that have manual sales
+func (pc *PriceCalcService) GetSalesEntry(
+	ctx context.Context,
+	day time.Time,
+	shift string,
+) (*viewmodels.SalesEntryViewModel, error) {
+	shift = strings.TrimSpace(shift)
+	products, err := pc.GetProductNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sales, err := pc.queries.GetSalesOfDay(ctx, db.GetSalesOfDayParams{
+		SoldOn: saleDay(day),
+		Shift:  shift,
+		Source: string(SaleSourceManual),
+	})
+	if err != nil {
+		return nil, err
+	}
+	days, err := pc.queries.GetSaleDays(ctx, string(SaleSourceManual))
+	if err != nil {
+		return nil, err
+	}
+
+	out := viewmodels.SalesEntryViewModel{
+		Day:        saleDay(day),
+		Shift:      shift,
+		Products:   products,
+		Quantities: make(map[int64]float64, len(sales)),
+		Revenues:   map[int64]float64{},
+		Days:       days,
+	}
+	for _, sale := range sales {
+		out.Quantities[sale.ProductID] = sale.Quantity
+		if sale.Revenue != nil {
+			out.Revenues[sale.ProductID] = *sale.Revenue
+		}
+	}
+	return &out, nil
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mike-jl/price_calc/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaleDay(t *testing.T) {
+	berlin := time.FixedZone("CEST", 2*60*60)
+	day := saleDay(time.Date(2026, 10, 1, 0, 30, 0, 0, berlin))
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC).Unix(), day)
+}
+
+func TestValidateManualSales(t *testing.T) {
+	products := map[int64]string{1: "Burger", 2: "Cola"}
+
+	assert.NoError(t, validateManualSales([]ManualSale{
+		{ProductID: 1, Quantity: 3, Revenue: utils.Ptr(30.0)},
+		{ProductID: 2},
+	}, products))
+	assert.ErrorContains(t, validateManualSales([]ManualSale{{ProductID: 3, Quantity: 1}}, products), "not found")
+	assert.ErrorContains(t, validateManualSales([]ManualSale{
+		{ProductID: 1, Quantity: 1},
+		{ProductID: 1, Quantity: 2},
+	}, products), "twice")
+	assert.ErrorContains(t, validateManualSales([]ManualSale{{ProductID: 1, Quantity: -1}}, products), "negative")
+	assert.ErrorContains(t, validateManualSales([]ManualSale{{ProductID: 2, Revenue: utils.Ptr(5.0)}}, products), "no quantity")
+}
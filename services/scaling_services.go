@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/mike-jl/price_calc/db"
+	"github.com/mike-jl/price_calc/internal/utils"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+// ScaleParams describes how a recipe is scaled. If Servings or Yield is set
+// the factor is derived from it, otherwise Factor is used as is.
+type ScaleParams struct {
+	Factor   float64
+	Servings float64
+	// Yield is the target amount in the unit with id YieldUnitID
+	Yield       float64
+	YieldUnitID int64
+	Expand      bool
+}
+
+// recipeLine is one usage of a product's recipe with its quantity in base
+// units. Base products and bundle components carry their own recipe in
+// children, already multiplied by the quantity of the line.
+type recipeLine struct {
+	name     string
+	quantity float64
+	// unitId and baseUnitId are 0 for bundle components, which are counted
+	// in pieces
+	unitId       int64
+	baseUnitId   int64
+	ingredientId int64
+	// productId is set for base products and bundle components
+	productId      *int64
+	unitPrice      *float64
+	priceTimeStamp *int64
+	duty           float64
+	cost           float64
+	baseProduct    bool
+	children       []recipeLine
+}
+
+// unitFamily returns the units that share the given base unit
+func unitFamily(baseUnitId int64, units []db.Unit) []db.Unit {
+	out := []db.Unit{}
+	for _, unit := range units {
+		if unit.ID == baseUnitId || utils.Deref(unit.BaseUnitID) == baseUnitId {
+			out = append(out, unit)
+		}
+	}
+	return out
+}
+
+// displayQuantity converts a quantity in base units to the unit of the same
+// family that shows the smallest amount of at least 1, e.g. 1.5 l instead of
+// 1500 ml and 5 cl instead of 0.05 l.
+func displayQuantity(quantity float64, baseUnitId int64, units []db.Unit) (float64, string) {
+	family := unitFamily(baseUnitId, units)
+	if len(family) == 0 {
+		return quantity, ""
+	}
+
+	best := family[0]
+	for _, unit := range family[1:] {
+		amount, bestAmount := quantity*unit.Factor, quantity*best.Factor
+		switch {
+		case bestAmount < 1 && amount > bestAmount:
+			best = unit
+		case amount >= 1 && amount < bestAmount:
+			best = unit
+		}
+	}
+	return math.Round(quantity*best.Factor*1000) / 1000, best.Name
+}
+
+// recipeWeight returns the weight of all usages in kg or l, expanding base
+// products, and the base unit of the first weighted usage.
+func recipeWeight(lines []recipeLine) (float64, int64) {
+	weight := 0.0
+	baseUnitId := int64(0)
+	for _, line := range lines {
+		if len(line.children) > 0 {
+			sub, subUnitId := recipeWeight(line.children)
+			weight += sub
+			if baseUnitId == 0 {
+				baseUnitId = subUnitId
+			}
+			continue
+		}
+		if isVolumeUnit(line.baseUnitId) {
+			weight += line.quantity
+			if baseUnitId == 0 {
+				baseUnitId = line.baseUnitId
+			}
+		}
+	}
+	return weight, baseUnitId
+}
+
+// scaleFactor derives the factor from the target servings or the target
+// yield, which is expected in kg or l like weight.
+func scaleFactor(params ScaleParams, servings float64, weight float64) (float64, error) {
+	factor := params.Factor
+	switch {
+	case params.Servings != 0:
+		if servings <= 0 {
+			return 0, errors.New("product has no servings to scale from")
+		}
+		factor = params.Servings / servings
+	case params.Yield != 0:
+		if weight <= 0 {
+			return 0, errors.New("product has no ingredients in kg or l to scale from")
+		}
+		factor = params.Yield / weight
+	}
+	if factor <= 0 {
+		return 0, errors.New("scale factor must be greater than 0")
+	}
+	return factor, nil
+}
+
+// scaleLines flattens the recipe lines, expanding base products if expand
+// is set, and converts the quantities to display units.
+func scaleLines(
+	lines []recipeLine,
+	factor float64,
+	expand bool,
+	depth int,
+	units []db.Unit,
+) []viewmodels.ScaledIngredient {
+	out := []viewmodels.ScaledIngredient{}
+	for _, line := range lines {
+		quantity, unit := line.quantity*factor, "×"
+		if line.baseUnitId != 0 {
+			quantity, unit = displayQuantity(quantity, line.baseUnitId, units)
+		}
+		out = append(out, viewmodels.ScaledIngredient{
+			Name:        line.name,
+			Quantity:    quantity,
+			Unit:        unit,
+			Cost:        line.cost * factor,
+			Depth:       depth,
+			BaseProduct: line.baseProduct,
+		})
+		if expand {
+			out = append(out, scaleLines(line.children, factor, expand, depth+1, units)...)
+		}
+	}
+	return out
+}
+
+func (pc *PriceCalcService) ScaleRecipe(
+	ctx context.Context,
+	productId int64,
+	params ScaleParams,
+) (*viewmodels.ScaledRecipe, error) {
+	product, err := pc.queries.GetProductWithCost(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+	units, err := pc.GetUnits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dutyRate, err := settingFloat(ctx, pc.queries, settingSpiritsDutyRate)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := pc.recipeLines(ctx, productId, 1, dutyRate, map[int64]bool{})
+	if err != nil {
+		return nil, err
+	}
+	if params.Yield != 0 {
+		unit, ok := utils.FirstPtr(units, func(u db.Unit) bool { return u.ID == params.YieldUnitID })
+		if !ok {
+			return nil, fmt.Errorf("unit with id %d not found", params.YieldUnitID)
+		}
+		baseUnitId := unit.ID
+		if unit.BaseUnitID != nil {
+			baseUnitId = *unit.BaseUnitID
+		}
+		if !isVolumeUnit(baseUnitId) {
+			return nil, fmt.Errorf("yield must be given in kg or l, not %s", unit.Name)
+		}
+		params.Yield /= unit.Factor
+	}
+	weight, weightUnitId := recipeWeight(lines)
+	factor, err := scaleFactor(params, product.Servings, weight)
+	if err != nil {
+		return nil, err
+	}
+
+	out := viewmodels.ScaledRecipe{
+		ProductID:   productId,
+		ProductName: product.Name,
+		Factor:      factor,
+		Servings:    product.Servings * factor,
+		Expanded:    params.Expand,
+		Ingredients: scaleLines(lines, factor, params.Expand, 0, units),
+		Units:       units,
+	}
+	for _, line := range lines {
+		out.Cost += line.cost * factor
+	}
+	if weight > 0 {
+		out.Yield, out.YieldUnit = displayQuantity(weight*factor, weightUnitId, units)
+	}
+	return &out, nil
+}
+
+// recipeLines walks the recipe like calculateProductCost does, multiplying
+// every quantity by multiplier.
+func (pc *PriceCalcService) recipeLines(
+	ctx context.Context,
+	productId int64,
+	multiplier float64,
+	dutyRate float64,
+	visited map[int64]bool,
+) ([]recipeLine, error) {
+	if visited[productId] {
+		return nil, fmt.Errorf("circular dependency detected on product %d", productId)
+	}
+	visited[productId] = true
+	defer delete(visited, productId)
+
+	usages, err := pc.queries.GetIngredientUsageForProductWithPrice(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []recipeLine{}
+	for _, usage := range usages {
+		line := recipeLine{
+			name:         utils.Deref(usage.Name),
+			quantity:     usage.Quantity * multiplier,
+			unitId:       usage.UnitID,
+			baseUnitId:   usage.BaseUnitID,
+			ingredientId: usage.IngredientID,
+		}
+		if usage.BaseProductID != nil {
+			line.baseProduct = true
+			line.productId = usage.BaseProductID
+			line.children, err = pc.recipeLines(ctx, *usage.BaseProductID, line.quantity, dutyRate, visited)
+			if err != nil {
+				return nil, err
+			}
+			for _, child := range line.children {
+				line.cost += child.cost
+			}
+		} else if usage.Price != nil {
+			line.unitPrice = usage.Price
+			line.priceTimeStamp = usage.TimeStamp
+			line.duty = ingredientDuty(usage, dutyRate) * multiplier
+			line.cost = *usage.Price*line.quantity + line.duty
+		} else {
+			return nil, fmt.Errorf("no price found for ingredient %d", usage.IngredientID)
+		}
+		lines = append(lines, line)
+	}
+
+	components, err := pc.queries.GetBundleComponents(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+	for _, component := range components {
+		line := recipeLine{
+			name:        component.Name,
+			quantity:    component.Quantity * multiplier,
+			productId:   &component.ProductID,
+			baseProduct: true,
+		}
+		line.children, err = pc.recipeLines(ctx, component.ProductID, line.quantity, dutyRate, visited)
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range line.children {
+			line.cost += child.cost
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/mike-jl/price_calc/db"
+	"github.com/stretchr/testify/assert"
+)
+
+var testUnits = []db.Unit{
+	{ID: 1, Name: "l", Factor: 1},
+	{ID: 2, Name: "ml", BaseUnitID: &[]int64{1}[0], Factor: 1000},
+	{ID: 3, Name: "cl", BaseUnitID: &[]int64{1}[0], Factor: 100},
+	{ID: 10, Name: "kg", Factor: 1},
+	{ID: 11, Name: "g", BaseUnitID: &[]int64{10}[0], Factor: 1000},
+}
+
+func TestDisplayQuantity(t *testing.T) {
+	tests := []struct {
+		quantity   float64
+		baseUnitId int64
+		amount     float64
+		unit       string
+	}{
+		{1.5, 1, 1.5, "l"},
+		{0.05, 1, 5, "cl"},
+		{0.004, 1, 4, "ml"},
+		{0.0001, 1, 0.1, "ml"},
+		{0.25, 10, 250, "g"},
+		{12, 10, 12, "kg"},
+		{0.1 + 0.2, 10, 300, "g"},
+	}
+	for _, test := range tests {
+		amount, unit := displayQuantity(test.quantity, test.baseUnitId, testUnits)
+		assert.Equal(t, test.amount, amount)
+		assert.Equal(t, test.unit, unit)
+	}
+
+	amount, unit := displayQuantity(3, 42, testUnits)
+	assert.Equal(t, 3.0, amount)
+	assert.Equal(t, "", unit)
+}
+
+func TestScaleFactor(t *testing.T) {
+	factor, err := scaleFactor(ScaleParams{Factor: 2.5}, 4, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2.5, factor)
+
+	factor, err = scaleFactor(ScaleParams{Factor: 1, Servings: 35}, 10, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3.5, factor)
+
+	factor, err = scaleFactor(ScaleParams{Factor: 1, Yield: 5}, 1, 0.25)
+	assert.NoError(t, err)
+	assert.Equal(t, 20.0, factor)
+
+	_, err = scaleFactor(ScaleParams{Yield: 5}, 1, 0)
+	assert.Error(t, err)
+	_, err = scaleFactor(ScaleParams{Servings: 5}, 0, 1)
+	assert.Error(t, err)
+	_, err = scaleFactor(ScaleParams{Factor: -1}, 1, 1)
+	assert.Error(t, err)
+}
+
+func TestScaleLines(t *testing.T) {
+	syrup := recipeLine{name: "Syrup", quantity: 0.02, baseUnitId: 1, cost: 0.1}
+	sugar := recipeLine{name: "Sugar", quantity: 0.01, baseUnitId: 10, cost: 0.02}
+	lines := []recipeLine{
+		{name: "Cola", quantity: 0.5, baseUnitId: 1, cost: 0.12, baseProduct: true,
+			children: []recipeLine{syrup, sugar}},
+		{name: "Ice", quantity: 0.1, baseUnitId: 10, cost: 0.01},
+	}
+
+	weight, baseUnitId := recipeWeight(lines)
+	assert.InDelta(t, 0.13, weight, 0.0001)
+	assert.Equal(t, int64(1), baseUnitId)
+
+	scaled := scaleLines(lines, 10, false, 0, testUnits)
+	assert.Len(t, scaled, 2)
+	assert.Equal(t, 5.0, scaled[0].Quantity)
+	assert.Equal(t, "l", scaled[0].Unit)
+	assert.InDelta(t, 1.2, scaled[0].Cost, 0.0001)
+
+	scaled = scaleLines(lines, 10, true, 0, testUnits)
+	assert.Len(t, scaled, 4)
+	assert.Equal(t, "Syrup", scaled[1].Name)
+	assert.Equal(t, 1, scaled[1].Depth)
+	assert.Equal(t, 20.0, scaled[1].Quantity)
+	assert.Equal(t, "cl", scaled[1].Unit)
+	assert.Equal(t, 100.0, scaled[2].Quantity)
+	assert.Equal(t, "g", scaled[2].Unit)
+	assert.Equal(t, "Ice", scaled[3].Name)
+	assert.Equal(t, 0, scaled[3].Depth)
+}
@@ -0,0 +1,380 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mike-jl/price_calc/db"
+	"github.com/mike-jl/price_calc/internal/utils"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+type ScenarioOverrideKind string
+
+const (
+	ScenarioOverridePercent ScenarioOverrideKind = "percent"
+	ScenarioOverridePrice   ScenarioOverrideKind = "price"
+)
+
+var errScenarioCommitted = errors.New("scenario is already committed")
+
+// overridePrice applies an override to a price per base unit
+func overridePrice(price float64, override db.ScenarioOverride) float64 {
+	if ScenarioOverrideKind(override.Kind) == ScenarioOverridePercent {
+		return price * (1 + override.Amount/100)
+	}
+	return override.Amount
+}
+
+// scenarioCost recalculates the cost of recipe lines with the overridden
+// ingredient prices, keyed by ingredient id.
+func scenarioCost(lines []recipeLine, overrides map[int64]db.ScenarioOverride) float64 {
+	cost := 0.0
+	for _, line := range lines {
+		if len(line.children) > 0 {
+			cost += scenarioCost(line.children, overrides)
+			continue
+		}
+		override, ok := overrides[line.ingredientId]
+		if !ok || line.unitPrice == nil {
+			cost += line.cost
+			continue
+		}
+		cost += overridePrice(*line.unitPrice, override)*line.quantity + line.duty
+	}
+	return cost
+}
+
+// compareScenario works out cost and margin of a product with the current
+// and the scenario ingredient cost. Margins are on the net dine in price.
+func compareScenario(
+	product db.Product,
+	currentCost float64,
+	scenarioCost float64,
+	vat float64,
+	settings viewmodels.CostSettings,
+) viewmodels.ScenarioComparison {
+	out := viewmodels.ScenarioComparison{
+		ProductID:    product.ID,
+		ProductName:  product.Name,
+		Price:        product.Price,
+		CurrentCost:  calculateCostBreakdown(product, currentCost, settings).Full,
+		ScenarioCost: calculateCostBreakdown(product, scenarioCost, settings).Full,
+	}
+	netPrice := product.Price / (1 + vat/100)
+	out.CurrentMargin = netPrice - out.CurrentCost
+	out.ScenarioMargin = netPrice - out.ScenarioCost
+	if netPrice != 0 {
+		out.CurrentMarginPercent = out.CurrentMargin / netPrice * 100
+		out.ScenarioMarginPercent = out.ScenarioMargin / netPrice * 100
+	}
+	return out
+}
+
+func validateScenarioOverride(kind ScenarioOverrideKind, amount float64) error {
+	switch kind {
+	case ScenarioOverridePercent:
+		if amount <= -100 {
+			return errors.New("price can not drop by 100% or more")
+		}
+	case ScenarioOverridePrice:
+		if amount < 0 {
+			return errors.New("price must not be negative")
+		}
+	default:
+		return errors.New("unknown override kind")
+	}
+	return nil
+}
+
+func (pc *PriceCalcService) GetScenarios(ctx context.Context) ([]db.Scenario, error) {
+	return pc.queries.GetScenarios(ctx)
+}
+
+func (pc *PriceCalcService) PutScenario(ctx context.Context, name string) (*db.Scenario, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("scenario name is empty")
+	}
+	scenario, err := pc.queries.InsertScenario(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return &scenario, nil
+}
+
+func (pc *PriceCalcService) DeleteScenario(ctx context.Context, scenarioId int64) error {
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	num, err := qtx.DeleteScenario(ctx, scenarioId)
+	if err != nil {
+		return err
+	}
+	if num < 1 {
+		return ErrNoRowsAffected
+	}
+	err = qtx.DeleteScenarioOverrides(ctx, scenarioId)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// getPricedIngredients returns the current price of every ingredient that
+// has one, base products are left out.
+func (pc *PriceCalcService) getPricedIngredients(
+	ctx context.Context,
+) (map[int64]viewmodels.IngredientWithPrices, error) {
+	ingredients, err := pc.GetIngredientsWithPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := map[int64]viewmodels.IngredientWithPrices{}
+	for _, ingredient := range ingredients {
+		if len(ingredient.Prices) == 0 || ingredient.Prices[0].Price == nil {
+			continue
+		}
+		out[ingredient.Ingredient.ID] = ingredient
+	}
+	return out, nil
+}
+
+func (pc *PriceCalcService) GetScenario(
+	ctx context.Context,
+	scenarioId int64,
+) (*viewmodels.ScenarioViewModel, error) {
+	scenario, err := pc.queries.GetScenario(ctx, scenarioId)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := pc.queries.GetScenarioOverrides(ctx, scenarioId)
+	if err != nil {
+		return nil, err
+	}
+	ingredients, err := pc.getPricedIngredients(ctx)
+	if err != nil {
+		return nil, err
+	}
+	units, err := pc.GetUnitsMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := viewmodels.ScenarioViewModel{
+		Scenario:    scenario,
+		Overrides:   make([]viewmodels.ScenarioOverride, 0, len(rows)),
+		Ingredients: make(map[int64]string, len(ingredients)),
+	}
+	for id, ingredient := range ingredients {
+		out.Ingredients[id] = ingredient.Ingredient.Name
+	}
+
+	overrides := make(map[int64]db.ScenarioOverride, len(rows))
+	for _, row := range rows {
+		override := db.ScenarioOverride{
+			ID:           row.ID,
+			ScenarioID:   row.ScenarioID,
+			IngredientID: row.IngredientID,
+			Kind:         row.Kind,
+			Amount:       row.Amount,
+		}
+		overrides[row.IngredientID] = override
+
+		current := ingredients[row.IngredientID].Prices
+		overrideRow := viewmodels.ScenarioOverride{GetScenarioOverridesRow: row}
+		if len(current) > 0 {
+			unit := units[current[0].UnitID]
+			overrideRow.BaseUnit = units[utils.Deref(unit.BaseUnitID)].Name
+			if unit.BaseUnitID == nil {
+				overrideRow.BaseUnit = unit.Name
+			}
+			overrideRow.CurrentPrice = utils.Deref(current[0].Price)
+			overrideRow.ScenarioPrice = overridePrice(overrideRow.CurrentPrice, override)
+		}
+		out.Overrides = append(out.Overrides, overrideRow)
+	}
+
+	out.Products, err = pc.compareScenarioProducts(ctx, overrides)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (pc *PriceCalcService) compareScenarioProducts(
+	ctx context.Context,
+	overrides map[int64]db.ScenarioOverride,
+) ([]viewmodels.ScenarioComparison, error) {
+	products, err := pc.GetProductsWithCost()
+	if err != nil {
+		return nil, err
+	}
+	settings, err := pc.GetCostSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := pc.GetCategories()
+	if err != nil {
+		return nil, err
+	}
+	resolver, err := pc.GetVatResolver(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	categoriesMap := make(map[int64]db.Category, len(categories))
+	for _, category := range categories {
+		categoriesMap[category.ID] = category
+	}
+
+	now := time.Now()
+	out := make([]viewmodels.ScenarioComparison, 0, len(products))
+	for _, product := range products {
+		lines, err := pc.recipeLines(ctx, product.Product.ID, 1, settings.SpiritsDutyRate, map[int64]bool{})
+		if err != nil {
+			return nil, err
+		}
+		currentCost := 0.0
+		for _, line := range lines {
+			currentCost += line.cost
+		}
+		vat := resolver.CategoryVat(categoriesMap[product.Product.CategoryID], VatChannelDineIn, now)
+		out = append(out, compareScenario(
+			product.Product,
+			currentCost,
+			scenarioCost(lines, overrides),
+			vat,
+			*settings,
+		))
+	}
+	return out, nil
+}
+
+func (pc *PriceCalcService) PutScenarioOverride(
+	ctx context.Context,
+	scenarioId int64,
+	ingredientId int64,
+	kind ScenarioOverrideKind,
+	amount float64,
+) error {
+	err := validateScenarioOverride(kind, amount)
+	if err != nil {
+		return err
+	}
+	scenario, err := pc.queries.GetScenario(ctx, scenarioId)
+	if err != nil {
+		return err
+	}
+	if scenario.CommittedAt != nil {
+		return errScenarioCommitted
+	}
+	ingredients, err := pc.getPricedIngredients(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := ingredients[ingredientId]; !ok {
+		return fmt.Errorf("ingredient with id %d has no price to override", ingredientId)
+	}
+
+	_, err = pc.queries.PutScenarioOverride(ctx, db.PutScenarioOverrideParams{
+		ScenarioID:   scenarioId,
+		IngredientID: ingredientId,
+		Kind:         string(kind),
+		Amount:       amount,
+	})
+	return err
+}
+
+// DeleteScenarioOverride returns the id of the scenario the override belonged to
+func (pc *PriceCalcService) DeleteScenarioOverride(ctx context.Context, overrideId int64) (int64, error) {
+	override, err := pc.queries.GetScenarioOverride(ctx, overrideId)
+	if err != nil {
+		return 0, err
+	}
+	scenario, err := pc.queries.GetScenario(ctx, override.ScenarioID)
+	if err != nil {
+		return 0, err
+	}
+	if scenario.CommittedAt != nil {
+		return 0, errScenarioCommitted
+	}
+	num, err := pc.queries.DeleteScenarioOverride(ctx, overrideId)
+	if err != nil {
+		return 0, err
+	}
+	if num < 1 {
+		return 0, ErrNoRowsAffected
+	}
+	return override.ScenarioID, nil
+}
+
+// CommitScenario writes the scenario prices as new ingredient prices and
+// updates the cost of every product.
+func (pc *PriceCalcService) CommitScenario(ctx context.Context, scenarioId int64) error {
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	num, err := qtx.CommitScenario(ctx, scenarioId)
+	if err != nil {
+		return err
+	}
+	if num < 1 {
+		return errScenarioCommitted
+	}
+
+	overrides, err := qtx.GetScenarioOverrides(ctx, scenarioId)
+	if err != nil {
+		return err
+	}
+	for _, override := range overrides {
+		rows, err := qtx.GetIngredientsWithPriceUnit(ctx, db.GetIngredientsWithPriceUnitParams{
+			IngredientID: override.IngredientID,
+			PriceLimit:   1,
+		})
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 || rows[0].Price == nil {
+			return fmt.Errorf("ingredient %s has no price to override", override.Name)
+		}
+		row := rows[0]
+
+		unit, err := qtx.GetUnit(ctx, *row.UnitID)
+		if err != nil {
+			return err
+		}
+		// the new price is per base unit, the price row is per pack including the deposit
+		price := overridePrice(*row.Price, db.ScenarioOverride{Kind: override.Kind, Amount: override.Amount})
+		packPrice := price*(*row.Quantity/unit.Factor) + utils.Deref(row.Deposit)
+		err = pc.insertIngredientPrice(ctx, qtx, &row, UpdateIngredientParams{
+			ID:       row.ID,
+			Price:    &packPrice,
+			Quantity: *row.Quantity,
+			UnitID:   *row.UnitID,
+			Deposit:  utils.Deref(row.Deposit),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = pc.updateAllProductCosts(ctx, qtx)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
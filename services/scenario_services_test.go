@@ -0,0 +1,54 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/mike-jl/price_calc/db"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioCost(t *testing.T) {
+	limePrice, ginPrice, tonicPrice := 4.0, 30.0, 2.0
+	lines := []recipeLine{
+		{name: "Gin", quantity: 0.04, ingredientId: 1, unitPrice: &ginPrice, duty: 0.5, cost: 1.7},
+		{name: "Mix", quantity: 1, baseProduct: true, cost: 0.4, children: []recipeLine{
+			{name: "Lime", quantity: 0.05, ingredientId: 2, unitPrice: &limePrice, cost: 0.2},
+			{name: "Tonic", quantity: 0.1, ingredientId: 3, unitPrice: &tonicPrice, cost: 0.2},
+		}},
+	}
+
+	assert.InDelta(t, 2.1, scenarioCost(lines, nil), 0.0001)
+
+	overrides := map[int64]db.ScenarioOverride{
+		1: {IngredientID: 1, Kind: "percent", Amount: -5},
+		2: {IngredientID: 2, Kind: "percent", Amount: 20},
+		3: {IngredientID: 3, Kind: "price", Amount: 3},
+	}
+	// gin 28.5 * 0.04 + duty, lime 4.8 * 0.05, tonic 3 * 0.1
+	assert.InDelta(t, 1.14+0.5+0.24+0.3, scenarioCost(lines, overrides), 0.0001)
+}
+
+func TestCompareScenario(t *testing.T) {
+	product := db.Product{ID: 1, Name: "G&T", Price: 11.9, PrepMinutes: 2}
+	settings := viewmodels.CostSettings{
+		LaborRate:     30,
+		OverheadRules: []db.OverheadRule{{Kind: "percent", Amount: 10}},
+	}
+
+	comparison := compareScenario(product, 2, 3, 19, settings)
+	assert.InDelta(t, 2+1+0.2, comparison.CurrentCost, 0.0001)
+	assert.InDelta(t, 3+1+0.3, comparison.ScenarioCost, 0.0001)
+	assert.InDelta(t, 10-3.2, comparison.CurrentMargin, 0.0001)
+	assert.InDelta(t, 10-4.3, comparison.ScenarioMargin, 0.0001)
+	assert.InDelta(t, 68, comparison.CurrentMarginPercent, 0.0001)
+	assert.InDelta(t, 57, comparison.ScenarioMarginPercent, 0.0001)
+}
+
+func TestValidateScenarioOverride(t *testing.T) {
+	assert.NoError(t, validateScenarioOverride(ScenarioOverridePercent, -50))
+	assert.NoError(t, validateScenarioOverride(ScenarioOverridePrice, 0))
+	assert.Error(t, validateScenarioOverride(ScenarioOverridePercent, -100))
+	assert.Error(t, validateScenarioOverride(ScenarioOverridePrice, -1))
+	assert.Error(t, validateScenarioOverride("fixed", 1))
+}
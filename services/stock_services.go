@@ -0,0 +1,353 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mike-jl/price_calc/db"
+	"github.com/mike-jl/price_calc/internal/utils"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+type StockValuation string
+
+const (
+	StockValuationLatest  StockValuation = "latest"
+	StockValuationAverage StockValuation = "average" // weighted by the received quantity
+	StockValuationFifo    StockValuation = "fifo"    // the stock is made of the newest receipts
+)
+
+const settingStockValuation = "stock_valuation"
+
+// stockReceipt is a goods receipt with its quantity in base units and its
+// price per base unit without deposit
+type stockReceipt struct {
+	quantity   float64
+	unitPrice  float64
+	receivedAt int64
+}
+
+// groupReceipts converts goods receipts to base units and groups them by
+// ingredient, keeping their order
+func groupReceipts(receipts []db.GetGoodsReceiptsRow, units UnitsMap) map[int64][]stockReceipt {
+	out := map[int64][]stockReceipt{}
+	for _, receipt := range receipts {
+		quantity := receipt.Quantity / units[receipt.UnitID].Factor
+		out[receipt.IngredientID] = append(out[receipt.IngredientID], stockReceipt{
+			quantity:   quantity,
+			unitPrice:  (receipt.Price - receipt.Deposit) / quantity,
+			receivedAt: receipt.ReceivedAt,
+		})
+	}
+	return out
+}
+
+// stockValue values quantity base units of an ingredient. receipts have to
+// be sorted newest first. Stock that no receipt accounts for is valued at
+// the latest price.
+func stockValue(
+	quantity float64,
+	receipts []stockReceipt,
+	latestPrice float64,
+	valuation StockValuation,
+) float64 {
+	switch valuation {
+	case StockValuationAverage:
+		received, paid := 0.0, 0.0
+		for _, receipt := range receipts {
+			received += receipt.quantity
+			paid += receipt.quantity * receipt.unitPrice
+		}
+		if received > 0 {
+			return quantity * paid / received
+		}
+	case StockValuationFifo:
+		value, remaining := 0.0, quantity
+		for _, receipt := range receipts {
+			if remaining <= 0 {
+				break
+			}
+			taken := min(remaining, receipt.quantity)
+			value += taken * receipt.unitPrice
+			remaining -= taken
+		}
+		return value + remaining*latestPrice
+	}
+	return quantity * latestPrice
+}
+
+type GoodsReceiptParams struct {
+	IngredientID int64
+	Quantity     float64
+	UnitID       int64
+	// Price is what was paid for Quantity including Deposit
+	Price      float64
+	Deposit    float64
+	Supplier   string
+	ReceivedAt time.Time
+}
+
+func validateGoodsReceipt(params GoodsReceiptParams) error {
+	if params.Quantity <= 0 {
+		return errors.New("quantity must be greater than 0")
+	}
+	if params.Price < 0 {
+		return errors.New("price must not be negative")
+	}
+	if params.Deposit < 0 {
+		return errors.New("deposit must not be negative")
+	}
+	if params.Deposit > params.Price {
+		return errors.New("deposit must not exceed the price")
+	}
+	return nil
+}
+
+// baseUnitOf returns the id of the base unit of a unit
+func baseUnitOf(unit db.Unit) int64 {
+	if unit.BaseUnitID != nil {
+		return *unit.BaseUnitID
+	}
+	return unit.ID
+}
+
+// checkStockUnit makes sure a quantity in unit can be added to the stock of
+// an ingredient, which is kept in the base unit of its price
+func checkStockUnit(
+	ctx context.Context,
+	qtx *db.Queries,
+	row db.GetIngredientsWithPriceUnitRow,
+	unit db.Unit,
+) error {
+	if row.BaseProductID != nil {
+		return fmt.Errorf("%s is a base product and has no stock", row.Name)
+	}
+	if row.UnitID == nil {
+		return nil
+	}
+	priceUnit, err := qtx.GetUnit(ctx, *row.UnitID)
+	if err != nil {
+		return err
+	}
+	if baseUnitOf(unit) != baseUnitOf(priceUnit) {
+		return fmt.Errorf("%s is not measured in %s", row.Name, unit.Name)
+	}
+	return nil
+}
+
+// ReceiveGoods records a delivery. The delivery price becomes the current
+// price of the ingredient and the quantity is added to its stock.
+func (pc *PriceCalcService) ReceiveGoods(ctx context.Context, params GoodsReceiptParams) error {
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	err = pc.receiveGoods(ctx, qtx, params, nil)
+	if err != nil {
+		return err
+	}
+	err = pc.updateAllProductCosts(ctx, qtx)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// receiveGoods books a goods receipt, purchaseOrderId is the order it was
+// delivered for. Only the newest receipt of an ingredient sets its price, the
+// product costs are not updated.
+func (pc *PriceCalcService) receiveGoods(
+	ctx context.Context,
+	qtx *db.Queries,
+	params GoodsReceiptParams,
+	purchaseOrderId *int64,
+) error {
+	err := validateGoodsReceipt(params)
+	if err != nil {
+		return err
+	}
+
+	rows, err := qtx.GetIngredientsWithPriceUnit(ctx, db.GetIngredientsWithPriceUnitParams{
+		IngredientID: params.IngredientID,
+		PriceLimit:   1,
+	})
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("ingredient with id %d not found", params.IngredientID)
+	}
+	row := rows[0]
+
+	unit, err := qtx.GetUnit(ctx, params.UnitID)
+	if err != nil {
+		return err
+	}
+	err = checkStockUnit(ctx, qtx, row, unit)
+	if err != nil {
+		return err
+	}
+
+	// a receipt entered after a newer delivery must not replace its price
+	latestReceivedAt, err := qtx.GetLatestGoodsReceiptAt(ctx, params.IngredientID)
+	if err != nil {
+		return err
+	}
+	if params.ReceivedAt.Unix() >= latestReceivedAt {
+		err = pc.insertIngredientPrice(ctx, qtx, &row, UpdateIngredientParams{
+			ID:       row.ID,
+			Price:    &params.Price,
+			Quantity: params.Quantity,
+			UnitID:   params.UnitID,
+			Deposit:  params.Deposit,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = qtx.InsertGoodsReceipt(ctx, db.InsertGoodsReceiptParams{
+		IngredientID:    params.IngredientID,
+		Quantity:        params.Quantity,
+		UnitID:          params.UnitID,
+		Price:           params.Price,
+		Deposit:         params.Deposit,
+		Supplier:        strings.TrimSpace(params.Supplier),
+		ReceivedAt:      params.ReceivedAt.Unix(),
+		PurchaseOrderID: purchaseOrderId,
+	})
+	if err != nil {
+		return err
+	}
+	return qtx.AddStock(ctx, db.AddStockParams{
+		IngredientID: params.IngredientID,
+		Quantity:     params.Quantity / unit.Factor,
+	})
+}
+
+// DeleteGoodsReceipt takes the received quantity out of stock again, the
+// price it set stays in the price history
+func (pc *PriceCalcService) DeleteGoodsReceipt(ctx context.Context, receiptId int64) error {
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	receipt, err := qtx.GetGoodsReceipt(ctx, receiptId)
+	if err != nil {
+		return err
+	}
+	unit, err := qtx.GetUnit(ctx, receipt.UnitID)
+	if err != nil {
+		return err
+	}
+	num, err := qtx.DeleteGoodsReceipt(ctx, receiptId)
+	if err != nil {
+		return err
+	}
+	if num < 1 {
+		return ErrNoRowsAffected
+	}
+	err = qtx.AddStock(ctx, db.AddStockParams{
+		IngredientID: receipt.IngredientID,
+		Quantity:     -receipt.Quantity / unit.Factor,
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (pc *PriceCalcService) GetGoodsReceipts(
+	ctx context.Context,
+) (*viewmodels.GoodsReceiptsViewModel, error) {
+	receipts, err := pc.queries.GetGoodsReceipts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ingredients, err := pc.getPricedIngredients(ctx)
+	if err != nil {
+		return nil, err
+	}
+	units, err := pc.GetUnits(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := viewmodels.GoodsReceiptsViewModel{
+		Receipts:    receipts,
+		Ingredients: make(map[int64]string, len(ingredients)),
+		Units:       units,
+	}
+	for id, ingredient := range ingredients {
+		out.Ingredients[id] = ingredient.Ingredient.Name
+	}
+	return &out, nil
+}
+
+// GetStock values the stock of every ingredient with the configured valuation
+func (pc *PriceCalcService) GetStock(ctx context.Context) (*viewmodels.StockViewModel, error) {
+	levels, err := pc.queries.GetStockLevels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := pc.queries.GetGoodsReceipts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ingredients, err := pc.getPricedIngredients(ctx)
+	if err != nil {
+		return nil, err
+	}
+	units, err := pc.GetUnits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := pc.GetCostSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	unitsMap := make(UnitsMap, len(units))
+	for _, unit := range units {
+		unitsMap[unit.ID] = unit
+	}
+	receiptsByIngredient := groupReceipts(receipts, unitsMap)
+
+	out := viewmodels.StockViewModel{
+		Valuation: settings.StockValuation,
+		Levels:    make([]viewmodels.StockLevel, 0, len(levels)),
+	}
+	for _, level := range levels {
+		ingredient, ok := ingredients[level.IngredientID]
+		if !ok {
+			continue
+		}
+		price := ingredient.Prices[0]
+		baseUnitId := baseUnitOf(unitsMap[price.UnitID])
+		row := viewmodels.StockLevel{
+			IngredientID: level.IngredientID,
+			Name:         level.Name,
+			Value: stockValue(
+				level.Quantity,
+				receiptsByIngredient[level.IngredientID],
+				utils.Deref(price.Price),
+				StockValuation(settings.StockValuation),
+			),
+		}
+		row.Quantity, row.Unit = displayQuantity(level.Quantity, baseUnitId, units)
+		out.Value += row.Value
+		out.Levels = append(out.Levels, row)
+	}
+	return &out, nil
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStockValue(t *testing.T) {
+	// newest first
+	receipts := []stockReceipt{
+		{quantity: 2, unitPrice: 12},
+		{quantity: 4, unitPrice: 9},
+	}
+
+	assert.InDelta(t, 50, stockValue(5, receipts, 10, StockValuationLatest), 0.0001)
+	assert.InDelta(t, 5*60.0/6, stockValue(5, receipts, 10, StockValuationAverage), 0.0001)
+	assert.InDelta(t, 2*12+3*9, stockValue(5, receipts, 10, StockValuationFifo), 0.0001)
+
+	// stock no receipt accounts for is valued at the latest price
+	assert.InDelta(t, 2*12+4*9+10, stockValue(7, receipts, 10, StockValuationFifo), 0.0001)
+	assert.InDelta(t, 30, stockValue(3, nil, 10, StockValuationAverage), 0.0001)
+	assert.InDelta(t, -10, stockValue(-1, receipts, 10, StockValuationFifo), 0.0001)
+}
+
+func TestValidateGoodsReceipt(t *testing.T) {
+	assert.NoError(t, validateGoodsReceipt(GoodsReceiptParams{Quantity: 6, Price: 20, Deposit: 1.5}))
+	assert.Error(t, validateGoodsReceipt(GoodsReceiptParams{Quantity: 0, Price: 20}))
+	assert.Error(t, validateGoodsReceipt(GoodsReceiptParams{Quantity: 6, Price: -1}))
+	assert.Error(t, validateGoodsReceipt(GoodsReceiptParams{Quantity: 6, Price: 1, Deposit: 2}))
+}
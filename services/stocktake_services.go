@@ -0,0 +1,353 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mike-jl/price_calc/db"
+	"github.com/mike-jl/price_calc/internal/utils"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+// DefaultShrinkageThreshold is the shrinkage in percent above which an
+// ingredient is flagged
+const DefaultShrinkageThreshold = 5.0
+
+var errStocktakeFinished = errors.New("stocktake is already finished")
+
+// stocktakeMovement is the stock of an ingredient between two stocktakes in
+// base units. expected is the stock level when the count was finished less
+// the theoretical consumption of the sales and comps since the opening count.
+type stocktakeMovement struct {
+	opening  float64
+	received float64
+	expected float64
+	counted  float64
+}
+
+// stocktakeVariance compares the theoretical usage, which expected accounts
+// for, with the usage the count shows. Shrinkage is the missing
+// quantity in percent of the theoretical usage, or of the available stock if
+// nothing should have been used.
+func stocktakeVariance(movement stocktakeMovement, threshold float64) viewmodels.StocktakeVariance {
+	available := movement.opening + movement.received
+	out := viewmodels.StocktakeVariance{
+		Opening:          movement.opening,
+		Received:         movement.received,
+		Expected:         movement.expected,
+		Counted:          movement.counted,
+		TheoreticalUsage: available - movement.expected,
+		ActualUsage:      available - movement.counted,
+		Variance:         movement.counted - movement.expected,
+	}
+	base := out.TheoreticalUsage
+	if base <= 0 {
+		base = available
+	}
+	if base > 0 {
+		shrinkage := -out.Variance / base * 100
+		out.Shrinkage = &shrinkage
+		out.Flagged = shrinkage > threshold
+	}
+	return out
+}
+
+func (pc *PriceCalcService) GetStocktakes(ctx context.Context) ([]db.Stocktake, error) {
+	return pc.queries.GetStocktakes(ctx)
+}
+
+func (pc *PriceCalcService) PutStocktake(ctx context.Context, countedAt time.Time) (*db.Stocktake, error) {
+	stocktake, err := pc.queries.InsertStocktake(ctx, countedAt.Unix())
+	if err != nil {
+		return nil, err
+	}
+	return &stocktake, nil
+}
+
+// DeleteStocktake deletes a stocktake that is not finished yet, finished ones
+// already changed the stock levels
+func (pc *PriceCalcService) DeleteStocktake(ctx context.Context, stocktakeId int64) error {
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	stocktake, err := qtx.GetStocktake(ctx, stocktakeId)
+	if err != nil {
+		return err
+	}
+	if stocktake.FinishedAt != nil {
+		return errStocktakeFinished
+	}
+	num, err := qtx.DeleteStocktake(ctx, stocktakeId)
+	if err != nil {
+		return err
+	}
+	if num < 1 {
+		return ErrNoRowsAffected
+	}
+	err = qtx.DeleteStocktakeCounts(ctx, stocktakeId)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// PutStocktakeCount sets the counted quantity of an ingredient, counting it
+// again replaces the earlier count
+func (pc *PriceCalcService) PutStocktakeCount(
+	ctx context.Context,
+	stocktakeId int64,
+	ingredientId int64,
+	quantity float64,
+	unitId int64,
+) error {
+	if quantity < 0 {
+		return errors.New("quantity must not be negative")
+	}
+	stocktake, err := pc.queries.GetStocktake(ctx, stocktakeId)
+	if err != nil {
+		return err
+	}
+	if stocktake.FinishedAt != nil {
+		return errStocktakeFinished
+	}
+	rows, err := pc.queries.GetIngredientsWithPriceUnit(ctx, db.GetIngredientsWithPriceUnitParams{
+		IngredientID: ingredientId,
+		PriceLimit:   1,
+	})
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("ingredient with id %d not found", ingredientId)
+	}
+	unit, err := pc.queries.GetUnit(ctx, unitId)
+	if err != nil {
+		return err
+	}
+	err = checkStockUnit(ctx, pc.queries, rows[0], unit)
+	if err != nil {
+		return err
+	}
+
+	_, err = pc.queries.PutStocktakeCount(ctx, db.PutStocktakeCountParams{
+		StocktakeID:  stocktakeId,
+		IngredientID: ingredientId,
+		Quantity:     quantity,
+		UnitID:       unitId,
+	})
+	return err
+}
+
+// DeleteStocktakeCount returns the id of the stocktake the count belonged to
+func (pc *PriceCalcService) DeleteStocktakeCount(ctx context.Context, countId int64) (int64, error) {
+	count, err := pc.queries.GetStocktakeCount(ctx, countId)
+	if err != nil {
+		return 0, err
+	}
+	stocktake, err := pc.queries.GetStocktake(ctx, count.StocktakeID)
+	if err != nil {
+		return 0, err
+	}
+	if stocktake.FinishedAt != nil {
+		return 0, errStocktakeFinished
+	}
+	num, err := pc.queries.DeleteStocktakeCount(ctx, countId)
+	if err != nil {
+		return 0, err
+	}
+	if num < 1 {
+		return 0, ErrNoRowsAffected
+	}
+	return count.StocktakeID, nil
+}
+
+// FinishStocktake remembers the stock levels the counts are compared to and
+// replaces them with the counted quantities
+func (pc *PriceCalcService) FinishStocktake(ctx context.Context, stocktakeId int64) error {
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	num, err := qtx.FinishStocktake(ctx, stocktakeId)
+	if err != nil {
+		return err
+	}
+	if num < 1 {
+		return errStocktakeFinished
+	}
+
+	levels, err := qtx.GetStockLevels(ctx)
+	if err != nil {
+		return err
+	}
+	expected := make(map[int64]float64, len(levels))
+	for _, level := range levels {
+		expected[level.IngredientID] = level.Quantity
+	}
+
+	counts, err := qtx.GetStocktakeCounts(ctx, stocktakeId)
+	if err != nil {
+		return err
+	}
+	for _, count := range counts {
+		err = qtx.SetStocktakeCountExpected(ctx, db.SetStocktakeCountExpectedParams{
+			Expected: utils.Ptr(expected[count.IngredientID]),
+			ID:       count.ID,
+		})
+		if err != nil {
+			return err
+		}
+		err = qtx.SetStock(ctx, db.SetStockParams{
+			IngredientID: count.IngredientID,
+			Quantity:     count.Quantity / count.Factor,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (pc *PriceCalcService) GetStocktake(
+	ctx context.Context,
+	stocktakeId int64,
+	threshold float64,
+) (*viewmodels.StocktakeViewModel, error) {
+	stocktake, err := pc.queries.GetStocktake(ctx, stocktakeId)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := pc.queries.GetStocktakeCounts(ctx, stocktakeId)
+	if err != nil {
+		return nil, err
+	}
+	ingredients, err := pc.getPricedIngredients(ctx)
+	if err != nil {
+		return nil, err
+	}
+	units, err := pc.GetUnits(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := viewmodels.StocktakeViewModel{
+		Stocktake:   stocktake,
+		Counts:      counts,
+		Ingredients: make(map[int64]string, len(ingredients)),
+		Units:       units,
+		Threshold:   threshold,
+		Variance:    []viewmodels.StocktakeVariance{},
+	}
+	for id, ingredient := range ingredients {
+		out.Ingredients[id] = ingredient.Ingredient.Name
+	}
+	if stocktake.FinishedAt == nil {
+		return &out, nil
+	}
+
+	out.Variance, err = pc.stocktakeVariances(ctx, stocktake, counts, ingredients, units, threshold)
+	if err != nil {
+		return nil, err
+	}
+	for _, variance := range out.Variance {
+		out.VarianceValue += variance.VarianceValue
+	}
+	return &out, nil
+}
+
+func (pc *PriceCalcService) stocktakeVariances(
+	ctx context.Context,
+	stocktake db.Stocktake,
+	counts []db.GetStocktakeCountsRow,
+	ingredients map[int64]viewmodels.IngredientWithPrices,
+	units []db.Unit,
+	threshold float64,
+) ([]viewmodels.StocktakeVariance, error) {
+	previous, err := pc.queries.GetPreviousStocktakeCounts(ctx, db.GetPreviousStocktakeCountsParams{
+		CountedAt:   stocktake.CountedAt,
+		StocktakeID: stocktake.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := pc.queries.GetGoodsReceipts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := pc.GetCostSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	unitsMap := make(UnitsMap, len(units))
+	for _, unit := range units {
+		unitsMap[unit.ID] = unit
+	}
+	// the counts are sorted latest first, the first one of an ingredient opens the period
+	opening := map[int64]db.GetPreviousStocktakeCountsRow{}
+	for _, count := range previous {
+		if _, ok := opening[count.IngredientID]; !ok {
+			opening[count.IngredientID] = count
+		}
+	}
+	receiptsByIngredient := groupReceipts(receipts, unitsMap)
+	// consumption of the sales and comps after the opening count by its date
+	consumed := map[int64]map[int64]float64{}
+
+	out := make([]viewmodels.StocktakeVariance, 0, len(counts))
+	for _, count := range counts {
+		movement := stocktakeMovement{
+			expected: utils.Deref(count.Expected),
+			counted:  count.Quantity / count.Factor,
+		}
+		since := int64(0)
+		if open, ok := opening[count.IngredientID]; ok {
+			movement.opening = open.Quantity / open.Factor
+			since = open.CountedAt
+		}
+		if _, ok := consumed[since]; !ok {
+			_, _, consumed[since], err = pc.periodConsumption(ctx, since+1, stocktake.CountedAt)
+			if err != nil {
+				return nil, err
+			}
+		}
+		// the stock levels do not account for sales and comps, so the book
+		// stock is lowered by what they should have used
+		movement.expected -= consumed[since][count.IngredientID]
+		// receipts up to the count, newest first, to value the stock with
+		onHand := []stockReceipt{}
+		for _, receipt := range receiptsByIngredient[count.IngredientID] {
+			if receipt.receivedAt > stocktake.CountedAt {
+				continue
+			}
+			onHand = append(onHand, receipt)
+			if receipt.receivedAt > since {
+				movement.received += receipt.quantity
+			}
+		}
+
+		variance := stocktakeVariance(movement, threshold)
+		variance.IngredientID = count.IngredientID
+		variance.Name = count.Name
+		if ingredient, ok := ingredients[count.IngredientID]; ok {
+			price := ingredient.Prices[0]
+			variance.Unit = unitsMap[baseUnitOf(unitsMap[price.UnitID])].Name
+			valuation := StockValuation(settings.StockValuation)
+			variance.VarianceValue = stockValue(movement.counted, onHand, utils.Deref(price.Price), valuation) -
+				stockValue(movement.expected, onHand, utils.Deref(price.Price), valuation)
+		}
+		out = append(out, variance)
+	}
+	return out, nil
+}
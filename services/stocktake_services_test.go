@@ -0,0 +1,34 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStocktakeVariance(t *testing.T) {
+	variance := stocktakeVariance(stocktakeMovement{
+		opening:  2,
+		received: 10,
+		expected: 4,
+		counted:  3,
+	}, 5)
+	assert.InDelta(t, 8, variance.TheoreticalUsage, 0.0001)
+	assert.InDelta(t, 9, variance.ActualUsage, 0.0001)
+	assert.InDelta(t, -1, variance.Variance, 0.0001)
+	assert.InDelta(t, 12.5, *variance.Shrinkage, 0.0001)
+	assert.True(t, variance.Flagged)
+
+	// more counted than expected is no shrinkage
+	variance = stocktakeVariance(stocktakeMovement{opening: 2, received: 10, expected: 4, counted: 4.2}, 5)
+	assert.InDelta(t, -2.5, *variance.Shrinkage, 0.0001)
+	assert.False(t, variance.Flagged)
+
+	// without theoretical usage the shrinkage is relative to the available stock
+	variance = stocktakeVariance(stocktakeMovement{opening: 2, received: 6, expected: 8, counted: 7}, 20)
+	assert.InDelta(t, 12.5, *variance.Shrinkage, 0.0001)
+	assert.False(t, variance.Flagged)
+
+	variance = stocktakeVariance(stocktakeMovement{}, 5)
+	assert.Nil(t, variance.Shrinkage)
+}
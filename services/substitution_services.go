@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mike-jl/price_calc/db"
+	"github.com/mike-jl/price_calc/internal/utils"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+// substitute is the ingredient that replaces another one, ratio is its
+// quantity per quantity of the replaced ingredient.
+type substitute struct {
+	unitPrice    float64
+	abv          *float64
+	dutyExcluded bool
+	ratio        float64
+}
+
+// substitutedCost recalculates the cost of recipe lines with every usage of
+// the ingredient fromId replaced.
+func substitutedCost(lines []recipeLine, fromId int64, to substitute, dutyRate float64) float64 {
+	cost := 0.0
+	for _, line := range lines {
+		switch {
+		case line.unitId != 0 && line.ingredientId == fromId:
+			quantity := line.quantity * to.ratio
+			cost += to.unitPrice*quantity + ingredientDuty(db.GetIngredientUsageForProductWithPriceRow{
+				Quantity:     quantity,
+				Abv:          to.abv,
+				DutyExcluded: &to.dutyExcluded,
+				BaseUnitID:   line.baseUnitId,
+			}, dutyRate)
+		case len(line.children) > 0:
+			cost += substitutedCost(line.children, fromId, to, dutyRate)
+		default:
+			cost += line.cost
+		}
+	}
+	return cost
+}
+
+// getSubstitute checks that toId can replace fromId and returns its price
+// per base unit. Base products are priced at their cached cost.
+func (pc *PriceCalcService) getSubstitute(
+	ctx context.Context,
+	ingredients map[int64]viewmodels.IngredientWithPrices,
+	fromId int64,
+	toId int64,
+	ratio float64,
+) (*substitute, error) {
+	if fromId == toId {
+		return nil, errors.New("an ingredient can not replace itself")
+	}
+	if ratio <= 0 {
+		return nil, errors.New("ratio must be greater than 0")
+	}
+	from, ok := ingredients[fromId]
+	if !ok {
+		return nil, fmt.Errorf("ingredient with id %d not found", fromId)
+	}
+	to, ok := ingredients[toId]
+	if !ok {
+		return nil, fmt.Errorf("ingredient with id %d not found", toId)
+	}
+
+	units, err := pc.GetUnitsMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	baseUnit := func(unitId int64) int64 {
+		if baseUnitId := units[unitId].BaseUnitID; baseUnitId != nil {
+			return *baseUnitId
+		}
+		return unitId
+	}
+	if baseUnit(from.Prices[0].UnitID) != baseUnit(to.Prices[0].UnitID) {
+		return nil, fmt.Errorf(
+			"%s and %s are not measured in the same units",
+			from.Ingredient.Name,
+			to.Ingredient.Name,
+		)
+	}
+
+	out := substitute{
+		abv:          to.Ingredient.Abv,
+		dutyExcluded: to.Ingredient.DutyExcluded,
+		ratio:        ratio,
+	}
+	if to.Prices[0].BaseProductID != nil {
+		cost, err := pc.queries.GetProductCost(ctx, *to.Prices[0].BaseProductID)
+		if err != nil {
+			return nil, err
+		}
+		out.unitPrice = cost.Cost
+	} else {
+		out.unitPrice = utils.Deref(to.Prices[0].Price)
+	}
+	return &out, nil
+}
+
+// getIngredientsWithCurrentPrice returns all ingredients that have a price,
+// including base products, keyed by id
+func (pc *PriceCalcService) getIngredientsWithCurrentPrice(
+	ctx context.Context,
+) (map[int64]viewmodels.IngredientWithPrices, error) {
+	ingredients, err := pc.GetIngredientsWithPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int64]viewmodels.IngredientWithPrices, len(ingredients))
+	for _, ingredient := range ingredients {
+		if len(ingredient.Prices) > 0 {
+			out[ingredient.Ingredient.ID] = ingredient
+		}
+	}
+	return out, nil
+}
+
+// GetSubstitution previews replacing one ingredient with another in every
+// product. Without both ingredients set only the ingredient names are returned.
+func (pc *PriceCalcService) GetSubstitution(
+	ctx context.Context,
+	fromId int64,
+	toId int64,
+	ratio float64,
+) (*viewmodels.SubstitutionViewModel, error) {
+	ingredients, err := pc.getIngredientsWithCurrentPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := viewmodels.SubstitutionViewModel{
+		FromID:      fromId,
+		ToID:        toId,
+		Ratio:       ratio,
+		Ingredients: make(map[int64]string, len(ingredients)),
+		Rows:        []viewmodels.SubstitutionRow{},
+	}
+	for id, ingredient := range ingredients {
+		out.Ingredients[id] = ingredient.Ingredient.Name
+	}
+	if fromId == 0 || toId == 0 {
+		return &out, nil
+	}
+
+	to, err := pc.getSubstitute(ctx, ingredients, fromId, toId, ratio)
+	if err != nil {
+		return nil, err
+	}
+	dutyRate, err := settingFloat(ctx, pc.queries, settingSpiritsDutyRate)
+	if err != nil {
+		return nil, err
+	}
+	products, err := pc.queries.GetProductNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	match := func(line recipeLine) bool { return line.unitId != 0 && line.ingredientId == fromId }
+	for _, product := range products {
+		lines, err := pc.recipeLines(ctx, product.ID, 1, dutyRate, map[int64]bool{})
+		if err != nil {
+			return nil, err
+		}
+		used := usageMatch{via: []string{}}
+		collectUsage(lines, match, nil, &used)
+		if !used.found {
+			continue
+		}
+		row := viewmodels.SubstitutionRow{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			Direct:      used.direct,
+			Via:         used.via,
+			NewCost:     substitutedCost(lines, fromId, *to, dutyRate),
+		}
+		for _, line := range lines {
+			row.CurrentCost += line.cost
+		}
+		out.Rows = append(out.Rows, row)
+	}
+	return &out, nil
+}
+
+// ApplySubstitution rewrites every usage of fromId to toId and returns the
+// number of usages changed
+func (pc *PriceCalcService) ApplySubstitution(
+	ctx context.Context,
+	fromId int64,
+	toId int64,
+	ratio float64,
+) (int64, error) {
+	ingredients, err := pc.getIngredientsWithCurrentPrice(ctx)
+	if err != nil {
+		return 0, err
+	}
+	_, err = pc.getSubstitute(ctx, ingredients, fromId, toId, ratio)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	num, err := qtx.SubstituteIngredientUsage(ctx, db.SubstituteIngredientUsageParams{
+		ToIngredientID:   toId,
+		Ratio:            ratio,
+		FromIngredientID: fromId,
+	})
+	if err != nil {
+		return 0, err
+	}
+	// this also fails if the swap made a product use itself
+	err = pc.updateAllProductCosts(ctx, qtx)
+	if err != nil {
+		return 0, err
+	}
+	return num, tx.Commit()
+}
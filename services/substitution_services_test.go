@@ -0,0 +1,35 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubstitutedCost(t *testing.T) {
+	ginPrice, tonicPrice := 30.0, 2.0
+	gin := recipeLine{name: "Gin", quantity: 0.04, unitId: 3, baseUnitId: 1, ingredientId: 1,
+		unitPrice: &ginPrice, cost: 1.2}
+	lines := []recipeLine{
+		gin,
+		{name: "Premix", quantity: 1, unitId: 1, baseUnitId: 1, ingredientId: 5, baseProduct: true, cost: 1.4,
+			children: []recipeLine{
+				gin,
+				{name: "Tonic", quantity: 0.1, unitId: 1, baseUnitId: 1, ingredientId: 3,
+					unitPrice: &tonicPrice, cost: 0.2},
+			}},
+	}
+
+	// cheaper gin, 10% more of it
+	cost := substitutedCost(lines, 1, substitute{unitPrice: 20, ratio: 1.1}, 0)
+	assert.InDelta(t, 2*20*0.044+0.2, cost, 0.0001)
+
+	// the new gin is not duty paid yet
+	abv := 40.0
+	cost = substitutedCost(lines, 1, substitute{unitPrice: 20, abv: &abv, dutyExcluded: true, ratio: 1}, 10)
+	assert.InDelta(t, 2*(20*0.04+0.04*0.4*10)+0.2, cost, 0.0001)
+
+	// replacing the base product as a whole
+	cost = substitutedCost(lines, 5, substitute{unitPrice: 1, ratio: 1}, 0)
+	assert.InDelta(t, 1.2+1, cost, 0.0001)
+}
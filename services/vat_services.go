@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/mike-jl/price_calc/db"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+type VatChannel string
+
+const (
+	VatChannelDineIn   VatChannel = "dine_in"
+	VatChannelTakeaway VatChannel = "takeaway"
+)
+
+// VatResolver picks the VAT rate of a category for a channel and a point in time.
+// Categories without a named rate, or dates before the first period of a rate,
+// fall back to the plain categories.vat value.
+type VatResolver struct {
+	periods map[int64][]db.VatRatePeriod
+}
+
+func newVatResolver(periods []db.VatRatePeriod) *VatResolver {
+	resolver := VatResolver{periods: map[int64][]db.VatRatePeriod{}}
+	for _, period := range periods {
+		resolver.periods[period.VatRateID] = append(resolver.periods[period.VatRateID], period)
+	}
+	return &resolver
+}
+
+// vatRateAt returns the rate of the latest period that started at or before at.
+// The periods have to be sorted by valid_from.
+func vatRateAt(periods []db.VatRatePeriod, at int64) (float64, bool) {
+	rate, ok := 0.0, false
+	for _, period := range periods {
+		if period.ValidFrom > at {
+			break
+		}
+		rate, ok = period.Rate, true
+	}
+	return rate, ok
+}
+
+func (r *VatResolver) Rate(vatRateId int64, at time.Time) (float64, bool) {
+	return vatRateAt(r.periods[vatRateId], at.Unix())
+}
+
+func (r *VatResolver) CategoryVat(
+	category db.Category,
+	channel VatChannel,
+	at time.Time,
+) float64 {
+	vatRateId := category.DineInVatRateID
+	if channel == VatChannelTakeaway {
+		vatRateId = category.TakeawayVatRateID
+	}
+	if vatRateId != nil {
+		if rate, ok := r.Rate(*vatRateId, at); ok {
+			return rate
+		}
+	}
+	return float64(category.Vat)
+}
+
+func (pc *PriceCalcService) GetVatResolver(ctx context.Context) (*VatResolver, error) {
+	periods, err := pc.queries.GetVatRatePeriods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newVatResolver(periods), nil
+}
+
+func (pc *PriceCalcService) GetCategoriesWithVat(
+	ctx context.Context,
+	at time.Time,
+) ([]viewmodels.CategoryWithVat, error) {
+	categories, err := pc.GetCategories()
+	if err != nil {
+		return nil, err
+	}
+	resolver, err := pc.GetVatResolver(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]viewmodels.CategoryWithVat, len(categories))
+	for i, category := range categories {
+		out[i] = resolver.categoryWithVat(category, at)
+	}
+	return out, nil
+}
+
+func (pc *PriceCalcService) GetCategoryWithVat(
+	ctx context.Context,
+	id int64,
+	at time.Time,
+) (*viewmodels.CategoryWithVat, error) {
+	category, err := pc.GetCategory(id)
+	if err != nil {
+		return nil, err
+	}
+	resolver, err := pc.GetVatResolver(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := resolver.categoryWithVat(*category, at)
+	return &out, nil
+}
+
+func (r *VatResolver) categoryWithVat(
+	category db.Category,
+	at time.Time,
+) viewmodels.CategoryWithVat {
+	return viewmodels.CategoryWithVat{
+		Category:    category,
+		DineInVat:   r.CategoryVat(category, VatChannelDineIn, at),
+		TakeawayVat: r.CategoryVat(category, VatChannelTakeaway, at),
+	}
+}
+
+func (pc *PriceCalcService) GetVatRates(ctx context.Context) ([]viewmodels.VatRateWithPeriods, error) {
+	rates, err := pc.queries.GetVatRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resolver, err := pc.GetVatResolver(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	out := make([]viewmodels.VatRateWithPeriods, len(rates))
+	for i, rate := range rates {
+		out[i] = viewmodels.VatRateWithPeriods{
+			VatRate: rate,
+			Periods: resolver.periods[rate.ID],
+		}
+		if current, ok := resolver.Rate(rate.ID, now); ok {
+			out[i].Current = &current
+		}
+	}
+	return out, nil
+}
+
+func (pc *PriceCalcService) PutVatRate(ctx context.Context, name string) (*db.VatRate, error) {
+	rate, err := pc.queries.PutVatRate(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+func (pc *PriceCalcService) PutVatRatePeriod(
+	ctx context.Context,
+	vatRateId int64,
+	rate float64,
+	validFrom time.Time,
+) (*db.VatRatePeriod, error) {
+	period, err := pc.queries.PutVatRatePeriod(ctx, db.PutVatRatePeriodParams{
+		VatRateID: vatRateId,
+		Rate:      rate,
+		ValidFrom: validFrom.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &period, nil
+}
+
+func (pc *PriceCalcService) DeleteVatRatePeriod(ctx context.Context, id int64) error {
+	num, err := pc.queries.DeleteVatRatePeriod(ctx, id)
+	if err != nil {
+		return err
+	}
+	if num < 1 {
+		return ErrNoRowsAffected
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mike-jl/price_calc/db"
+	"github.com/mike-jl/price_calc/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVatResolverCategoryVat(t *testing.T) {
+	resolver := newVatResolver([]db.VatRatePeriod{
+		{ID: 1, VatRateID: 1, Rate: 19, ValidFrom: date(2007, 1, 1).Unix()},
+		{ID: 2, VatRateID: 1, Rate: 16, ValidFrom: date(2020, 7, 1).Unix()},
+		{ID: 3, VatRateID: 1, Rate: 19, ValidFrom: date(2021, 1, 1).Unix()},
+		{ID: 4, VatRateID: 2, Rate: 7, ValidFrom: date(2007, 1, 1).Unix()},
+		{ID: 5, VatRateID: 3, Rate: 19, ValidFrom: date(2020, 1, 1).Unix()},
+		{ID: 6, VatRateID: 3, Rate: 7, ValidFrom: date(2026, 1, 1).Unix()},
+	})
+
+	tests := []struct {
+		name     string
+		category db.Category
+		channel  VatChannel
+		at       time.Time
+		expected float64
+	}{
+		{
+			name:     "No named rate falls back to category vat",
+			category: db.Category{ID: 1, Vat: 19},
+			channel:  VatChannelDineIn,
+			at:       date(2025, 1, 1),
+			expected: 19,
+		},
+		{
+			name: "Dine-in rate before change",
+			category: db.Category{
+				ID:                1,
+				Vat:               19,
+				DineInVatRateID:   utils.Ptr(int64(3)),
+				TakeawayVatRateID: utils.Ptr(int64(2)),
+			},
+			channel:  VatChannelDineIn,
+			at:       date(2025, 12, 31),
+			expected: 19,
+		},
+		{
+			name: "Dine-in rate on the day of the change",
+			category: db.Category{
+				ID:                1,
+				Vat:               19,
+				DineInVatRateID:   utils.Ptr(int64(3)),
+				TakeawayVatRateID: utils.Ptr(int64(2)),
+			},
+			channel:  VatChannelDineIn,
+			at:       date(2026, 1, 1),
+			expected: 7,
+		},
+		{
+			name: "Takeaway uses its own rate",
+			category: db.Category{
+				ID:                1,
+				Vat:               19,
+				DineInVatRateID:   utils.Ptr(int64(3)),
+				TakeawayVatRateID: utils.Ptr(int64(2)),
+			},
+			channel:  VatChannelTakeaway,
+			at:       date(2025, 1, 1),
+			expected: 7,
+		},
+		{
+			name:     "Temporary reduction is picked by date",
+			category: db.Category{ID: 1, Vat: 0, DineInVatRateID: utils.Ptr(int64(1))},
+			channel:  VatChannelDineIn,
+			at:       date(2020, 10, 1),
+			expected: 16,
+		},
+		{
+			name:     "Date before the first period falls back to category vat",
+			category: db.Category{ID: 1, Vat: 10, DineInVatRateID: utils.Ptr(int64(3))},
+			channel:  VatChannelDineIn,
+			at:       date(2019, 1, 1),
+			expected: 10,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, resolver.CategoryVat(tc.category, tc.channel, tc.at))
+		})
+	}
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mike-jl/price_calc/db"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+type WasteReason string
+
+const (
+	WasteReasonBreakage       WasteReason = "breakage"
+	WasteReasonExpired        WasteReason = "expired"
+	WasteReasonSpillage       WasteReason = "spillage"
+	WasteReasonOverproduction WasteReason = "overproduction"
+	WasteReasonOther          WasteReason = "other"
+)
+
+// WasteReasons are the reasons waste can be logged with, in display order
+var WasteReasons = []WasteReason{
+	WasteReasonBreakage,
+	WasteReasonExpired,
+	WasteReasonSpillage,
+	WasteReasonOverproduction,
+	WasteReasonOther,
+}
+
+// WasteParams describe wasted stock, either an ingredient in UnitID or a
+// product in pieces
+type WasteParams struct {
+	IngredientID *int64
+	ProductID    *int64
+	Quantity     float64
+	UnitID       *int64
+	Reason       string
+	WastedOn     time.Time
+	Staff        string
+}
+
+func validateWaste(params WasteParams) error {
+	if (params.IngredientID == nil) == (params.ProductID == nil) {
+		return errors.New("either an ingredient or a product must be wasted")
+	}
+	if params.IngredientID != nil && params.UnitID == nil {
+		return errors.New("the unit of the ingredient is required")
+	}
+	if params.Quantity <= 0 {
+		return errors.New("quantity must be greater than 0")
+	}
+	if !slices.Contains(WasteReasons, WasteReason(params.Reason)) {
+		return fmt.Errorf("unknown reason %q", params.Reason)
+	}
+	return nil
+}
+
+// wasteByReason sums the waste per reason, the costliest reason first
+func wasteByReason(entries []db.GetWasteRow) []viewmodels.WasteReasonTotal {
+	totals := map[string]*viewmodels.WasteReasonTotal{}
+	for _, entry := range entries {
+		total, ok := totals[entry.Reason]
+		if !ok {
+			total = &viewmodels.WasteReasonTotal{Reason: entry.Reason}
+			totals[entry.Reason] = total
+		}
+		total.Entries++
+		total.Cost += entry.Cost
+	}
+	out := make([]viewmodels.WasteReasonTotal, 0, len(totals))
+	for _, total := range totals {
+		out = append(out, *total)
+	}
+	slices.SortFunc(out, func(a, b viewmodels.WasteReasonTotal) int {
+		return cmp.Or(cmp.Compare(b.Cost, a.Cost), strings.Compare(a.Reason, b.Reason))
+	})
+	return out
+}
+
+// LogWaste values the waste at the current cost and takes it out of stock.
+// Wasted products take their ingredients out of stock. Only ingredients
+// with a stock level are reduced, the others are not kept in inventory.
+func (pc *PriceCalcService) LogWaste(ctx context.Context, params WasteParams) error {
+	err := validateWaste(params)
+	if err != nil {
+		return err
+	}
+
+	// base units per ingredient
+	used := map[int64]float64{}
+	cost := 0.0
+	if params.ProductID != nil {
+		// valued like the product cost, including the spirits duty
+		dutyRate, err := settingFloat(ctx, pc.queries, settingSpiritsDutyRate)
+		if err != nil {
+			return err
+		}
+		lines, err := pc.recipeLines(ctx, *params.ProductID, params.Quantity, dutyRate, map[int64]bool{})
+		if err != nil {
+			return err
+		}
+		sumConsumption(lines, used)
+		for _, line := range lines {
+			cost += line.cost
+		}
+	} else {
+		rows, err := pc.queries.GetIngredientsWithPriceUnit(ctx, db.GetIngredientsWithPriceUnitParams{
+			IngredientID: *params.IngredientID,
+			PriceLimit:   1,
+		})
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return fmt.Errorf("ingredient with id %d not found", *params.IngredientID)
+		}
+		unit, err := pc.queries.GetUnit(ctx, *params.UnitID)
+		if err != nil {
+			return err
+		}
+		err = checkStockUnit(ctx, pc.queries, rows[0], unit)
+		if err != nil {
+			return err
+		}
+		if rows[0].Price == nil {
+			return fmt.Errorf("%s has no price to value the waste with", rows[0].Name)
+		}
+		used[*params.IngredientID] = params.Quantity / unit.Factor
+		cost = used[*params.IngredientID] * *rows[0].Price
+	}
+	if params.ProductID != nil {
+		params.UnitID = nil
+	}
+
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	waste, err := qtx.InsertWaste(ctx, db.InsertWasteParams{
+		IngredientID: params.IngredientID,
+		ProductID:    params.ProductID,
+		Quantity:     params.Quantity,
+		UnitID:       params.UnitID,
+		Reason:       params.Reason,
+		WastedOn:     saleDay(params.WastedOn),
+		Staff:        strings.TrimSpace(params.Staff),
+		Cost:         cost,
+	})
+	if err != nil {
+		return err
+	}
+	for ingredientId, quantity := range used {
+		num, err := qtx.SubtractStock(ctx, db.SubtractStockParams{
+			Quantity:     quantity,
+			IngredientID: ingredientId,
+		})
+		if err != nil {
+			return err
+		}
+		if num < 1 {
+			continue
+		}
+		err = qtx.InsertWasteStock(ctx, db.InsertWasteStockParams{
+			WasteID:      waste.ID,
+			IngredientID: ingredientId,
+			Quantity:     quantity,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteWaste puts what the waste took out of stock back
+func (pc *PriceCalcService) DeleteWaste(ctx context.Context, wasteId int64) error {
+	tx, err := pc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := pc.queries.WithTx(tx)
+
+	stock, err := qtx.GetWasteStock(ctx, wasteId)
+	if err != nil {
+		return err
+	}
+	for _, taken := range stock {
+		err = qtx.AddStock(ctx, db.AddStockParams{
+			IngredientID: taken.IngredientID,
+			Quantity:     taken.Quantity,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	err = qtx.DeleteWasteStock(ctx, wasteId)
+	if err != nil {
+		return err
+	}
+	num, err := qtx.DeleteWaste(ctx, wasteId)
+	if err != nil {
+		return err
+	}
+	if num < 1 {
+		return ErrNoRowsAffected
+	}
+	return tx.Commit()
+}
+
+// GetWaste reports the waste logged from the first to the last day
+func (pc *PriceCalcService) GetWaste(
+	ctx context.Context,
+	from time.Time,
+	to time.Time,
+) (*viewmodels.WasteViewModel, error) {
+	if to.Before(from) {
+		return nil, errors.New("the period must not end before it starts")
+	}
+	entries, err := pc.queries.GetWaste(ctx, db.GetWasteParams{
+		FromDay: saleDay(from),
+		ToDay:   saleDay(to),
+	})
+	if err != nil {
+		return nil, err
+	}
+	ingredients, err := pc.getPricedIngredients(ctx)
+	if err != nil {
+		return nil, err
+	}
+	products, err := pc.GetProductNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	units, err := pc.GetUnits(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := viewmodels.WasteViewModel{
+		From:        saleDay(from),
+		To:          saleDay(to),
+		Entries:     entries,
+		ByReason:    wasteByReason(entries),
+		Ingredients: make(map[int64]string, len(ingredients)),
+		Products:    products,
+		Units:       units,
+		Reasons:     make([]string, len(WasteReasons)),
+	}
+	for _, entry := range entries {
+		out.Cost += entry.Cost
+	}
+	for id, ingredient := range ingredients {
+		out.Ingredients[id] = ingredient.Ingredient.Name
+	}
+	for i, reason := range WasteReasons {
+		out.Reasons[i] = string(reason)
+	}
+	return &out, nil
+}
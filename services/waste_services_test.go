@@ -0,0 +1,39 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/mike-jl/price_calc/db"
+	"github.com/mike-jl/price_calc/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateWaste(t *testing.T) {
+	ingredient, product, unit := int64(1), int64(2), int64(10)
+
+	assert.NoError(t, validateWaste(WasteParams{IngredientID: &ingredient, UnitID: &unit, Quantity: 1, Reason: "expired"}))
+	assert.NoError(t, validateWaste(WasteParams{ProductID: &product, Quantity: 2, Reason: "spillage"}))
+	assert.ErrorContains(t, validateWaste(WasteParams{Quantity: 1, Reason: "other"}), "either")
+	assert.ErrorContains(t, validateWaste(WasteParams{
+		IngredientID: &ingredient, ProductID: &product, UnitID: &unit, Quantity: 1, Reason: "other",
+	}), "either")
+	assert.ErrorContains(t, validateWaste(WasteParams{IngredientID: &ingredient, Quantity: 1, Reason: "other"}), "unit")
+	assert.ErrorContains(t, validateWaste(WasteParams{ProductID: &product, Reason: "other"}), "quantity")
+	assert.ErrorContains(t, validateWaste(WasteParams{ProductID: &product, Quantity: 1, Reason: "stolen"}), "reason")
+}
+
+func TestWasteByReason(t *testing.T) {
+	totals := wasteByReason([]db.GetWasteRow{
+		{Reason: "expired", Cost: 2},
+		{Reason: "breakage", Cost: 5, ProductID: utils.Ptr(int64(1))},
+		{Reason: "expired", Cost: 1.5},
+		{Reason: "spillage", Cost: 3.5},
+	})
+	assert.Len(t, totals, 3)
+	assert.Equal(t, "breakage", totals[0].Reason)
+	// ties are sorted by reason
+	assert.Equal(t, "expired", totals[1].Reason)
+	assert.Equal(t, 2, totals[1].Entries)
+	assert.InDelta(t, 3.5, totals[1].Cost, 1e-9)
+	assert.Equal(t, "spillage", totals[2].Reason)
+}
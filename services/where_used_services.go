@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/mike-jl/price_calc/db"
+	viewmodels "github.com/mike-jl/price_calc/viewModels"
+)
+
+// usageMatch sums up all lines of a recipe tree that match
+type usageMatch struct {
+	found    bool
+	quantity float64
+	cost     float64
+	// baseUnitId of the first match, 0 for bundle components
+	baseUnitId int64
+	direct     bool
+	via        []string
+}
+
+// collectUsage adds all lines matching to out. Lines that don't match are
+// searched through their children, path holds the names of the products
+// passed on the way.
+func collectUsage(lines []recipeLine, match func(recipeLine) bool, path []string, out *usageMatch) {
+	for _, line := range lines {
+		if !match(line) {
+			collectUsage(line.children, match, append(slices.Clip(path), line.name), out)
+			continue
+		}
+		if !out.found {
+			out.baseUnitId = line.baseUnitId
+		}
+		out.found = true
+		out.quantity += line.quantity
+		out.cost += line.cost
+		if len(path) == 0 {
+			out.direct = true
+		} else if via := strings.Join(path, " › "); !slices.Contains(out.via, via) {
+			out.via = append(out.via, via)
+		}
+	}
+}
+
+func (pc *PriceCalcService) GetIngredientWhereUsed(
+	ctx context.Context,
+	ingredientId int64,
+) (*viewmodels.WhereUsed, error) {
+	ingredient, err := pc.queries.GetIngredient(ctx, ingredientId)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := pc.whereUsed(ctx, func(line recipeLine) bool {
+		return line.unitId != 0 && line.ingredientId == ingredientId
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &viewmodels.WhereUsed{
+		Name:         ingredient.Name,
+		IngredientID: &ingredientId,
+		Rows:         rows,
+	}, nil
+}
+
+// GetProductWhereUsed lists the products using a product as base product or
+// bundle component
+func (pc *PriceCalcService) GetProductWhereUsed(
+	ctx context.Context,
+	productId int64,
+) (*viewmodels.WhereUsed, error) {
+	product, err := pc.queries.GetProductWithCost(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := pc.whereUsed(ctx, func(line recipeLine) bool {
+		return line.productId != nil && *line.productId == productId
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &viewmodels.WhereUsed{
+		Name:      product.Name,
+		ProductID: &productId,
+		Rows:      rows,
+	}, nil
+}
+
+func (pc *PriceCalcService) whereUsed(
+	ctx context.Context,
+	match func(recipeLine) bool,
+) ([]viewmodels.WhereUsedRow, error) {
+	products, err := pc.queries.GetProductNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	units, err := pc.GetUnits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dutyRate, err := settingFloat(ctx, pc.queries, settingSpiritsDutyRate)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []viewmodels.WhereUsedRow{}
+	for _, product := range products {
+		lines, err := pc.recipeLines(ctx, product.ID, 1, dutyRate, map[int64]bool{})
+		if err != nil {
+			return nil, err
+		}
+		used := usageMatch{via: []string{}}
+		collectUsage(lines, match, nil, &used)
+		if !used.found {
+			continue
+		}
+		out = append(out, whereUsedRow(product, lines, used, units))
+	}
+	return out, nil
+}
+
+func whereUsedRow(
+	product db.GetProductNamesRow,
+	lines []recipeLine,
+	used usageMatch,
+	units []db.Unit,
+) viewmodels.WhereUsedRow {
+	row := viewmodels.WhereUsedRow{
+		ProductID:   product.ID,
+		ProductName: product.Name,
+		Quantity:    used.quantity,
+		Unit:        "×",
+		Cost:        used.cost,
+		Direct:      used.direct,
+		Via:         used.via,
+	}
+	if used.baseUnitId != 0 {
+		row.Quantity, row.Unit = displayQuantity(used.quantity, used.baseUnitId, units)
+	}
+	total := 0.0
+	for _, line := range lines {
+		total += line.cost
+	}
+	if total > 0 {
+		row.Share = used.cost / total * 100
+	}
+	return row
+}
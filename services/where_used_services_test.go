@@ -0,0 +1,54 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/mike-jl/price_calc/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectUsage(t *testing.T) {
+	sauceId, saladId := int64(7), int64(8)
+	oil := func(quantity, cost float64) recipeLine {
+		return recipeLine{name: "Oil", quantity: quantity, unitId: 11, baseUnitId: 10, ingredientId: 1, cost: cost}
+	}
+	lines := []recipeLine{
+		oil(0.01, 0.05),
+		{name: "Sauce", quantity: 0.1, unitId: 10, baseUnitId: 10, ingredientId: 2, productId: &sauceId,
+			cost: 0.5, baseProduct: true, children: []recipeLine{oil(0.02, 0.1), {name: "Vinegar", cost: 0.4}}},
+		{name: "Salad", quantity: 1, productId: &saladId, cost: 1, baseProduct: true,
+			children: []recipeLine{
+				{name: "Sauce", quantity: 0.05, unitId: 10, baseUnitId: 10, ingredientId: 2, productId: &sauceId,
+					cost: 0.25, baseProduct: true, children: []recipeLine{oil(0.01, 0.05)}},
+				{name: "Lettuce", cost: 0.75},
+			}},
+	}
+
+	used := usageMatch{}
+	collectUsage(lines, func(line recipeLine) bool { return line.ingredientId == 1 }, nil, &used)
+	assert.True(t, used.found)
+	assert.True(t, used.direct)
+	assert.InDelta(t, 0.04, used.quantity, 0.0001)
+	assert.InDelta(t, 0.2, used.cost, 0.0001)
+	assert.Equal(t, int64(10), used.baseUnitId)
+	assert.Equal(t, []string{"Sauce", "Salad › Sauce"}, used.via)
+
+	row := whereUsedRow(db.GetProductNamesRow{ID: 1, Name: "Bowl"}, lines, used, testUnits)
+	assert.Equal(t, 40.0, row.Quantity)
+	assert.Equal(t, "g", row.Unit)
+	assert.InDelta(t, 0.2/1.55*100, row.Share, 0.0001)
+
+	// the sauce is matched as a whole, its oil is not counted again
+	used = usageMatch{}
+	collectUsage(lines, func(line recipeLine) bool {
+		return line.productId != nil && *line.productId == sauceId
+	}, nil, &used)
+	assert.True(t, used.direct)
+	assert.InDelta(t, 0.15, used.quantity, 0.0001)
+	assert.InDelta(t, 0.75, used.cost, 0.0001)
+	assert.Equal(t, []string{"Salad"}, used.via)
+
+	used = usageMatch{}
+	collectUsage(lines, func(line recipeLine) bool { return line.ingredientId == 99 }, nil, &used)
+	assert.False(t, used.found)
+}
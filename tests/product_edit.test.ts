@@ -10,16 +10,46 @@ const minimalModel: ProductEditViewModel = {
             price: 0,
             multiplicator: 1,
             category_id: 1,
+            prep_minutes: 0,
+            deposit: 0,
+            servings: 1,
         },
         cost: 0,
+        breakdown: { ingredients: 0, labor: 0, overhead: 0, full: 0, basis: 0 },
+        allergens: [],
     },
     categories: [
-        { id: 1, name: 'Test Category', vat: 0 },
-        { id: 2, name: 'Another Category', vat: 0 },
+        {
+            id: 1,
+            name: 'Test Category',
+            vat: 0,
+            dine_in_vat_rate_id: null,
+            takeaway_vat_rate_id: null,
+            dine_in_vat: 0,
+            takeaway_vat: 0,
+        },
+        {
+            id: 2,
+            name: 'Another Category',
+            vat: 0,
+            dine_in_vat_rate_id: null,
+            takeaway_vat_rate_id: null,
+            dine_in_vat: 0,
+            takeaway_vat: 0,
+        },
     ],
     ingredient_usages: [],
     ingredients: {},
     units: {},
+    cost_settings: {
+        labor_rate: 0,
+        cost_basis: 'ingredients',
+        stock_valuation: 'latest',
+        spirits_duty_rate: 0,
+        standard_drink_grams: 10,
+        overhead_rules: [],
+    },
+    alcohol: { volume: 0, pure_alcohol: 0, abv: 0, standard_drinks: 0, duty: 0 },
 };
 
 describe('productCost', () => {
@@ -49,7 +79,7 @@ describe('productCost', () => {
         ];
         vm.ingredients = {
             1: {
-                ingredient: { id: 1, name: 'test1' },
+                ingredient: { id: 1, name: 'test1', abv: null, duty_excluded: false },
                 prices: [{
                     id: 1,
                     price: 5,
@@ -57,11 +87,12 @@ describe('productCost', () => {
                     quantity: 3,
                     unit_id: 1,
                     ingredient_id: 1,
-                    base_product_id: null
+                    base_product_id: null,
+                    deposit: 0
                 }],
             },
             2: {
-                ingredient: { id: 2, name: 'test2' },
+                ingredient: { id: 2, name: 'test2', abv: null, duty_excluded: false },
                 prices: [{
                     id: 2,
                     price: 3,
@@ -69,12 +100,104 @@ describe('productCost', () => {
                     quantity: 3,
                     unit_id: 1,
                     ingredient_id: 2,
-                    base_product_id: null
+                    base_product_id: null,
+                    deposit: 0
                 }],
             },
         };
 
         expect(vm.productCost).toBe('19.00'); // 5*2 + 3*3 = 10 + 9 = 19
     });
+
+    it('adds the duty of direct usages only', () => {
+        const vm = createProductEditModel({
+            ...minimalModel,
+            units: {
+                1: { id: 1, name: 'l', base_unit_id: null, factor: 1 },
+                2: { id: 2, name: 'Stk', base_unit_id: null, factor: 1 },
+            },
+            cost_settings: { ...minimalModel.cost_settings, spirits_duty_rate: 10 },
+            alcohol: { volume: 0.1, pure_alcohol: 0.04, abv: 20, standard_drinks: 3.2, duty: 0.4 },
+        });
+        vm.ingredient_usages_ext = [
+            { id: 1, ingredient_id: 1, quantity: 0.1, unit_id: 1, product_id: 1, editing: false, displayAmount: '0.10' },
+            { id: 2, ingredient_id: 2, quantity: 1, unit_id: 2, product_id: 1, editing: false, displayAmount: '1.00' },
+        ];
+        vm.ingredients = {
+            1: {
+                ingredient: { id: 1, name: 'Rum', abv: 40, duty_excluded: true },
+                prices: [{
+                    id: 1, price: 20, time_stamp: 5, quantity: 1, unit_id: 1,
+                    ingredient_id: 1, base_product_id: null, deposit: 0,
+                }],
+            },
+            // a base product, its price already includes the duty
+            2: {
+                ingredient: { id: 2, name: 'Punch', abv: 10, duty_excluded: true },
+                prices: [{
+                    id: 2, price: 1.5, time_stamp: 5, quantity: 1, unit_id: 2,
+                    ingredient_id: 2, base_product_id: 3, deposit: 0,
+                }],
+            },
+        };
+
+        // 20*0.1 + 0.1*40% * 10 + 1.5 = 2 + 0.4 + 1.5
+        expect(vm.productCost).toBe('3.90');
+    });
 });
 
+
+describe('cost breakdown', () => {
+    it('adds labor and overhead to the ingredient cost', () => {
+        const vm = createProductEditModel({
+            ...minimalModel,
+            product: {
+                ...minimalModel.product,
+                product: { ...minimalModel.product.product, prep_minutes: 6 },
+            },
+            cost_settings: {
+                ...minimalModel.cost_settings,
+                labor_rate: 20,
+                cost_basis: 'prime',
+                overhead_rules: [
+                    { id: 1, name: 'Energy', kind: 'fixed', amount: 0.5, category_id: null },
+                    { id: 2, name: 'Packaging', kind: 'percent', amount: 10, category_id: 1 },
+                    { id: 3, name: 'Other', kind: 'fixed', amount: 5, category_id: 2 },
+                ],
+            },
+        });
+        vm.ingredient_usages_ext = [
+            {
+                id: 1,
+                ingredient_id: 1,
+                quantity: 2,
+                unit_id: 1,
+                product_id: 1,
+                editing: false,
+                displayAmount: '2.00',
+            },
+        ];
+        vm.ingredients = {
+            1: {
+                ingredient: { id: 1, name: 'test1', abv: null, duty_excluded: false },
+                prices: [{
+                    id: 1,
+                    price: 5,
+                    time_stamp: 5,
+                    quantity: 1,
+                    unit_id: 1,
+                    ingredient_id: 1,
+                    base_product_id: null,
+                    deposit: 0
+                }],
+            },
+        };
+        vm.selectedCat = 0;
+
+        expect(vm.productCost).toBe('10.00');
+        expect(vm.laborCost).toBe('2.00'); // 6 min at 20/h
+        expect(vm.overheadCost).toBe('1.50'); // 0.5 + 10% of 10
+        expect(vm.fullCost).toBe('13.50');
+        expect(vm.basisCost).toBe('12.00');
+    });
+});
@@ -0,0 +1,11 @@
+package viewmodels
+
+type AlcoholInfo struct {
+	// Volume and PureAlcohol are in liters
+	Volume         float64 `json:"volume"`
+	PureAlcohol    float64 `json:"pure_alcohol"`
+	Abv            float64 `json:"abv"`
+	StandardDrinks float64 `json:"standard_drinks"`
+	// Duty is the spirits duty of ingredients whose price excludes it
+	Duty float64 `json:"duty"`
+}
@@ -0,0 +1,30 @@
+package viewmodels
+
+import "github.com/mike-jl/price_calc/db"
+
+type BundleComponent struct {
+	ID        int64   `json:"id"`
+	ProductID int64   `json:"product_id"`
+	Name      string  `json:"name"`
+	Quantity  float64 `json:"quantity"`
+	Price     float64 `json:"price"`
+	Cost      float64 `json:"cost"`
+	Vat       float64 `json:"vat"`
+}
+
+type Bundle struct {
+	Product         db.Product        `json:"product"`
+	Components      []BundleComponent `json:"components"`
+	Cost            float64           `json:"cost"`
+	IndividualPrice float64           `json:"individual_price"`
+	Discount        float64           `json:"discount"`
+	DiscountPercent float64           `json:"discount_percent"`
+	BlendedVat      float64           `json:"blended_vat"`
+	NetPrice        float64           `json:"net_price"`
+	Margin          float64           `json:"margin"`
+}
+
+type BundleEditViewModel struct {
+	Bundle       Bundle           `json:"bundle"`
+	ProductNames map[int64]string `json:"product_names"`
+}
@@ -0,0 +1,50 @@
+package viewmodels
+
+import "github.com/mike-jl/price_calc/db"
+
+type ChannelMargin struct {
+	ChannelID       int64   `json:"channel_id"`
+	ChannelName     string  `json:"channel_name"`
+	Price           float64 `json:"price"`
+	PriceOverridden bool    `json:"price_overridden"`
+	Vat             float64 `json:"vat"`
+	NetPrice        float64 `json:"net_price"`
+	Fees            float64 `json:"fees"`
+	Cost            float64 `json:"cost"`
+	Packaging       float64 `json:"packaging"`
+	Margin          float64 `json:"margin"`
+	MarginPercent   float64 `json:"margin_percent"`
+
+	PackagingItems []PackagingItem `json:"packaging_items"`
+}
+
+type PackagingItem struct {
+	ID           int64   `json:"id"`
+	IngredientID int64   `json:"ingredient_id"`
+	Name         string  `json:"name"`
+	Quantity     float64 `json:"quantity"`
+	UnitID       int64   `json:"unit_id"`
+	Cost         float64 `json:"cost"`
+}
+
+type ProductChannelsViewModel struct {
+	Product     ProductWithChannelMargins `json:"product"`
+	Ingredients map[int64]string          `json:"ingredients"`
+	Units       map[int64]db.Unit         `json:"units"`
+}
+
+type ProductWithChannelMargins struct {
+	Product ProductWithCost `json:"product"`
+	Margins []ChannelMargin `json:"margins"`
+}
+
+type ChannelSummary struct {
+	Channel              db.SalesChannel `json:"channel"`
+	TotalMargin          float64         `json:"total_margin"`
+	AverageMarginPercent float64         `json:"average_margin_percent"`
+}
+
+type ChannelReport struct {
+	Channels []ChannelSummary            `json:"channels"`
+	Products []ProductWithChannelMargins `json:"products"`
+}
@@ -0,0 +1,19 @@
+package viewmodels
+
+import "github.com/mike-jl/price_calc/db"
+
+// CompMonth is the comp cost of a month, Costs is in the order of the reasons
+type CompMonth struct {
+	Month string    `json:"month"`
+	Costs []float64 `json:"costs"`
+	Cost  float64   `json:"cost"`
+}
+
+type CompsViewModel struct {
+	Month    string           `json:"month"`
+	Entries  []db.GetCompsRow `json:"entries"`
+	Cost     float64          `json:"cost"`
+	Months   []CompMonth      `json:"months"`
+	Products map[int64]string `json:"products"`
+	Reasons  []string         `json:"reasons"`
+}
@@ -0,0 +1,26 @@
+package viewmodels
+
+import "github.com/mike-jl/price_calc/db"
+
+// ConsumptionLine is the theoretical consumption of an ingredient in the
+// unit it is bought in, Cost is nil without a current price
+type ConsumptionLine struct {
+	IngredientID int64    `json:"ingredient_id"`
+	Name         string   `json:"name"`
+	Quantity     float64  `json:"quantity"`
+	Unit         string   `json:"unit"`
+	PackSize     float64  `json:"pack_size"`
+	Packs        float64  `json:"packs"`
+	Cost         *float64 `json:"cost"`
+}
+
+type ConsumptionViewModel struct {
+	From  int64                    `json:"from"`
+	To    int64                    `json:"to"`
+	Sales []db.GetSalesInPeriodRow `json:"sales"`
+	Comps []db.GetCompsInPeriodRow `json:"comps"`
+	Lines []ConsumptionLine        `json:"lines"`
+	Cost  float64                  `json:"cost"`
+	// Unpriced is the number of lines the cost leaves out
+	Unpriced int `json:"unpriced"`
+}
@@ -0,0 +1,29 @@
+package viewmodels
+
+// CostNode is one usage in the cost tree of a product. Quantities and costs
+// are what the root product uses, not per unit of the parent.
+type CostNode struct {
+	Name string `json:"name"`
+	// Kind is one of "ingredient", "base_product" or "bundle_component"
+	Kind         string  `json:"kind"`
+	IngredientID *int64  `json:"ingredient_id"`
+	ProductID    *int64  `json:"product_id"`
+	Quantity     float64 `json:"quantity"`
+	Unit         string  `json:"unit"`
+	BaseQuantity float64 `json:"base_quantity"`
+	BaseUnit     string  `json:"base_unit"`
+	// UnitPrice is the price per base unit of the ingredient price used
+	UnitPrice      *float64   `json:"unit_price"`
+	PriceTimeStamp *int64     `json:"price_time_stamp"`
+	Duty           float64    `json:"duty"`
+	Cost           float64    `json:"cost"`
+	Share          float64    `json:"share"`
+	Children       []CostNode `json:"children"`
+}
+
+type CostTree struct {
+	ProductID   int64      `json:"product_id"`
+	ProductName string     `json:"product_name"`
+	Cost        float64    `json:"cost"`
+	Nodes       []CostNode `json:"nodes"`
+}
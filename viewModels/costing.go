@@ -0,0 +1,26 @@
+package viewmodels
+
+import "github.com/mike-jl/price_calc/db"
+
+type CostBreakdown struct {
+	Ingredients float64 `json:"ingredients"`
+	Labor       float64 `json:"labor"`
+	Overhead    float64 `json:"overhead"`
+	Full        float64 `json:"full"`
+	// Basis is the part of the cost the multiplicator is applied to
+	Basis float64 `json:"basis"`
+}
+
+type CostSettings struct {
+	LaborRate          float64           `json:"labor_rate"`
+	CostBasis          string            `json:"cost_basis"`
+	StockValuation     string            `json:"stock_valuation"`
+	SpiritsDutyRate    float64           `json:"spirits_duty_rate"`
+	StandardDrinkGrams float64           `json:"standard_drink_grams"`
+	OverheadRules      []db.OverheadRule `json:"overhead_rules"`
+}
+
+type CostingViewModel struct {
+	Settings   CostSettings     `json:"settings"`
+	Categories map[int64]string `json:"categories"`
+}
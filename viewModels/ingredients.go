@@ -8,13 +8,17 @@ type IngredientWithPrices struct {
 }
 
 type IngredientWithPrice struct {
-	ID    int64              `json:"id"`
-	Name  string             `json:"name"`
-	Price db.IngredientPrice `json:"price"`
+	ID           int64              `json:"id"`
+	Name         string             `json:"name"`
+	Abv          *float64           `json:"abv"`
+	DutyExcluded bool               `json:"duty_excluded"`
+	Allergens    []int64            `json:"allergens"`
+	Price        db.IngredientPrice `json:"price"`
 }
 
 type IngredientsViewModel struct {
 	Ingredients  []IngredientWithPrice `json:"ingredients"`
 	Units        map[int64]db.Unit     `json:"units"`
 	ProductNames map[int64]string      `json:"product_names"`
+	Allergens    []db.Allergen         `json:"allergens"`
 }
@@ -0,0 +1,35 @@
+package viewmodels
+
+type MenuEngineeringItem struct {
+	ProductID int64   `json:"product_id"`
+	Name      string  `json:"name"`
+	Sold      float64 `json:"sold"`
+	// MenuMix is the share of the sales of the category in percent
+	MenuMix     float64 `json:"menu_mix"`
+	Price       float64 `json:"price"`
+	NetPrice    float64 `json:"net_price"`
+	Cost        float64 `json:"cost"`
+	Margin      float64 `json:"margin"`
+	TotalMargin float64 `json:"total_margin"`
+	Class       string  `json:"class"`
+}
+
+// MenuEngineeringCategory is the matrix of one category, a product is
+// popular from MixThreshold and profitable from AverageMargin on
+type MenuEngineeringCategory struct {
+	CategoryID    int64                 `json:"category_id"`
+	Name          string                `json:"name"`
+	Sold          float64               `json:"sold"`
+	MixThreshold  float64               `json:"mix_threshold"`
+	AverageMargin float64               `json:"average_margin"`
+	TotalMargin   float64               `json:"total_margin"`
+	Items         []MenuEngineeringItem `json:"items"`
+}
+
+type MenuEngineeringViewModel struct {
+	From       int64                     `json:"from"`
+	To         int64                     `json:"to"`
+	CategoryID *int64                    `json:"category_id"`
+	Categories map[int64]string          `json:"categories"`
+	Sections   []MenuEngineeringCategory `json:"sections"`
+}
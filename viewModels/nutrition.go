@@ -0,0 +1,30 @@
+package viewmodels
+
+import "github.com/mike-jl/price_calc/db"
+
+type NutritionFacts struct {
+	Kcal    float64 `json:"kcal"`
+	Fat     float64 `json:"fat"`
+	Carbs   float64 `json:"carbs"`
+	Sugar   float64 `json:"sugar"`
+	Protein float64 `json:"protein"`
+	Salt    float64 `json:"salt"`
+}
+
+type ProductNutrition struct {
+	Total      NutritionFacts `json:"total"`
+	PerServing NutritionFacts `json:"per_serving"`
+	Per100     NutritionFacts `json:"per_100"`
+	// Weight of all usages in kg or l
+	Weight   float64 `json:"weight"`
+	Servings float64 `json:"servings"`
+	// Missing holds the ingredients without nutrition values or that are not
+	// used by weight or volume, the totals do not include them
+	Missing []string `json:"missing"`
+}
+
+type IngredientNutritionRow struct {
+	ID        int64                   `json:"id"`
+	Name      string                  `json:"name"`
+	Nutrition *db.IngredientNutrition `json:"nutrition"`
+}
@@ -0,0 +1,18 @@
+package viewmodels
+
+import "github.com/mike-jl/price_calc/db"
+
+type PriceListEntry struct {
+	ProductID  int64   `json:"product_id"`
+	Name       string  `json:"name"`
+	Category   string  `json:"category"`
+	Vat        float64 `json:"vat"`
+	Price      float64 `json:"price"`
+	NetPrice   float64 `json:"net_price"`
+	Deposit    float64 `json:"deposit"`
+	DepositNet float64 `json:"deposit_net"`
+	Total      float64 `json:"total"`
+	TotalVat   float64 `json:"total_vat"`
+
+	Allergens []db.Allergen `json:"allergens"`
+}
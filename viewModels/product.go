@@ -3,14 +3,20 @@ package viewmodels
 import "github.com/mike-jl/price_calc/db"
 
 type ProductWithCost struct {
-	Product db.Product `json:"product"`
-	Cost    float64    `json:"cost"`
+	Product   db.Product    `json:"product"`
+	Cost      float64       `json:"cost"`
+	Breakdown CostBreakdown `json:"breakdown"`
+	Allergens []db.Allergen `json:"allergens"`
 }
 
 type ProductEditViewModel struct {
 	Product          ProductWithCost                `json:"product"`
-	Categories       []db.Category                  `json:"categories"`
+	Categories       []CategoryWithVat              `json:"categories"`
 	IngredientUsages []db.IngredientUsage           `json:"ingredient_usages"`
 	Ingredients      map[int64]IngredientWithPrices `json:"ingredients"`
 	Units            map[int64]db.Unit              `json:"units"`
+	CostSettings     CostSettings                   `json:"cost_settings"`
+	Alcohol          AlcoholInfo                    `json:"alcohol"`
+	Nutrition        ProductNutrition               `json:"nutrition"`
+	Recipe           RecipeViewModel                `json:"recipe"`
 }
@@ -0,0 +1,41 @@
+package viewmodels
+
+import "github.com/mike-jl/price_calc/db"
+
+// ParLevel is a par level with the current stock in its unit
+type ParLevel struct {
+	ID           int64   `json:"id"`
+	IngredientID int64   `json:"ingredient_id"`
+	Name         string  `json:"name"`
+	Supplier     string  `json:"supplier"`
+	Par          float64 `json:"par"`
+	ReorderPoint float64 `json:"reorder_point"`
+	PackSize     float64 `json:"pack_size"`
+	Unit         string  `json:"unit"`
+	Stock        float64 `json:"stock"`
+	OnOrder      float64 `json:"on_order"`
+	Reorder      bool    `json:"reorder"`
+}
+
+type PurchasingViewModel struct {
+	ParLevels   []ParLevel                `json:"par_levels"`
+	Orders      []db.GetPurchaseOrdersRow `json:"orders"`
+	Ingredients map[int64]string          `json:"ingredients"`
+	Units       []db.Unit                 `json:"units"`
+}
+
+type PurchaseOrderLine struct {
+	ID           int64   `json:"id"`
+	IngredientID int64   `json:"ingredient_id"`
+	Name         string  `json:"name"`
+	Quantity     float64 `json:"quantity"`
+	Unit         string  `json:"unit"`
+	Packs        float64 `json:"packs"`
+	ExpectedCost float64 `json:"expected_cost"`
+}
+
+type PurchaseOrderViewModel struct {
+	Order        db.PurchaseOrder    `json:"order"`
+	Lines        []PurchaseOrderLine `json:"lines"`
+	ExpectedCost float64             `json:"expected_cost"`
+}
@@ -0,0 +1,18 @@
+package viewmodels
+
+import "github.com/mike-jl/price_calc/db"
+
+type RecipeIngredient struct {
+	Name     string  `json:"name"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+}
+
+type RecipeViewModel struct {
+	ProductID   int64              `json:"product_id"`
+	ProductName string             `json:"product_name"`
+	Recipe      db.ProductRecipe   `json:"recipe"`
+	Steps       []db.RecipeStep    `json:"steps"`
+	Photos      []db.ProductPhoto  `json:"photos"`
+	Ingredients []RecipeIngredient `json:"ingredients"`
+}
@@ -0,0 +1,40 @@
+package viewmodels
+
+type ReverseCostingLine struct {
+	Name     string  `json:"name"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+	// MaxQuantity is the quantity in Unit if every usage shrinks by the same factor
+	MaxQuantity float64 `json:"max_quantity"`
+	Cost        float64 `json:"cost"`
+	// UnitPrice and MaxUnitPrice are per PriceUnit without duty. MaxUnitPrice
+	// is the price at which this usage alone meets the budget, nil if even a
+	// free source would not be enough.
+	UnitPrice    float64  `json:"unit_price"`
+	MaxUnitPrice *float64 `json:"max_unit_price"`
+	PriceUnit    string   `json:"price_unit"`
+	BaseProduct  bool     `json:"base_product"`
+}
+
+type ReverseCosting struct {
+	GrossPrice    float64 `json:"gross_price"`
+	CategoryID    int64   `json:"category_id"`
+	Vat           float64 `json:"vat"`
+	Multiplicator float64 `json:"multiplicator"`
+	CostRatio     float64 `json:"cost_ratio"`
+	CostBasis     string  `json:"cost_basis"`
+	// Calculated is false until a price and category are given
+	Calculated       bool    `json:"calculated"`
+	NetPrice         float64 `json:"net_price"`
+	BasisBudget      float64 `json:"basis_budget"`
+	IngredientBudget float64 `json:"ingredient_budget"`
+
+	ProductID    *int64               `json:"product_id"`
+	ProductName  string               `json:"product_name"`
+	CurrentCost  float64              `json:"current_cost"`
+	ShrinkFactor float64              `json:"shrink_factor"`
+	Lines        []ReverseCostingLine `json:"lines"`
+
+	Categories []CategoryWithVat `json:"-"`
+	Products   map[int64]string  `json:"-"`
+}
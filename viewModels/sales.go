@@ -0,0 +1,40 @@
+package viewmodels
+
+import "github.com/mike-jl/price_calc/db"
+
+// PosSettings map the columns of a POS export by their header
+type PosSettings struct {
+	Delimiter      string `json:"delimiter"`
+	DateFormat     string `json:"date_format"`
+	DateColumn     string `json:"date_column"`
+	ItemColumn     string `json:"item_column"`
+	NameColumn     string `json:"name_column"`
+	QuantityColumn string `json:"quantity_column"`
+	RevenueColumn  string `json:"revenue_column"`
+}
+
+type PosImportResult struct {
+	From     int64    `json:"from"`
+	To       int64    `json:"to"`
+	Lines    int      `json:"lines"`
+	Sales    int      `json:"sales"`
+	Unmapped []string `json:"unmapped"`
+}
+
+type PosImportViewModel struct {
+	Settings PosSettings      `json:"settings"`
+	Items    []db.PosItem     `json:"items"`
+	Products map[int64]string `json:"products"`
+	Result   *PosImportResult `json:"result"`
+}
+
+// SalesEntryViewModel holds the manually entered sales of a day and shift
+// by product id
+type SalesEntryViewModel struct {
+	Day        int64               `json:"day"`
+	Shift      string              `json:"shift"`
+	Products   map[int64]string    `json:"products"`
+	Quantities map[int64]float64   `json:"quantities"`
+	Revenues   map[int64]float64   `json:"revenues"`
+	Days       []db.GetSaleDaysRow `json:"days"`
+}
@@ -0,0 +1,27 @@
+package viewmodels
+
+import "github.com/mike-jl/price_calc/db"
+
+type ScaledIngredient struct {
+	Name     string  `json:"name"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+	Cost     float64 `json:"cost"`
+	// Depth is 0 for the product's own usages and grows with every base
+	// product that is expanded
+	Depth       int  `json:"depth"`
+	BaseProduct bool `json:"base_product"`
+}
+
+type ScaledRecipe struct {
+	ProductID   int64              `json:"product_id"`
+	ProductName string             `json:"product_name"`
+	Factor      float64            `json:"factor"`
+	Servings    float64            `json:"servings"`
+	Yield       float64            `json:"yield"`
+	YieldUnit   string             `json:"yield_unit"`
+	Cost        float64            `json:"cost"`
+	Expanded    bool               `json:"expanded"`
+	Ingredients []ScaledIngredient `json:"ingredients"`
+	Units       []db.Unit          `json:"units"`
+}
@@ -0,0 +1,32 @@
+package viewmodels
+
+import "github.com/mike-jl/price_calc/db"
+
+type ScenarioOverride struct {
+	db.GetScenarioOverridesRow
+	// prices are per base unit
+	CurrentPrice  float64 `json:"current_price"`
+	ScenarioPrice float64 `json:"scenario_price"`
+	BaseUnit      string  `json:"base_unit"`
+}
+
+type ScenarioComparison struct {
+	ProductID   int64   `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	Price       float64 `json:"price"`
+	// costs are full costs including labor and overhead
+	CurrentCost           float64 `json:"current_cost"`
+	ScenarioCost          float64 `json:"scenario_cost"`
+	CurrentMargin         float64 `json:"current_margin"`
+	ScenarioMargin        float64 `json:"scenario_margin"`
+	CurrentMarginPercent  float64 `json:"current_margin_percent"`
+	ScenarioMarginPercent float64 `json:"scenario_margin_percent"`
+}
+
+type ScenarioViewModel struct {
+	Scenario  db.Scenario          `json:"scenario"`
+	Overrides []ScenarioOverride   `json:"overrides"`
+	Products  []ScenarioComparison `json:"products"`
+	// Ingredients holds the names of all ingredients with a price
+	Ingredients map[int64]string `json:"ingredients"`
+}
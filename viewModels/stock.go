@@ -0,0 +1,51 @@
+package viewmodels
+
+import "github.com/mike-jl/price_calc/db"
+
+type StockLevel struct {
+	IngredientID int64   `json:"ingredient_id"`
+	Name         string  `json:"name"`
+	Quantity     float64 `json:"quantity"`
+	Unit         string  `json:"unit"`
+	Value        float64 `json:"value"`
+}
+
+type StockViewModel struct {
+	Valuation string       `json:"valuation"`
+	Levels    []StockLevel `json:"levels"`
+	Value     float64      `json:"value"`
+}
+
+type GoodsReceiptsViewModel struct {
+	Receipts    []db.GetGoodsReceiptsRow `json:"receipts"`
+	Ingredients map[int64]string         `json:"ingredients"`
+	Units       []db.Unit                `json:"units"`
+}
+
+// StocktakeVariance quantities are in the base unit Unit
+type StocktakeVariance struct {
+	IngredientID     int64   `json:"ingredient_id"`
+	Name             string  `json:"name"`
+	Unit             string  `json:"unit"`
+	Opening          float64 `json:"opening"`
+	Received         float64 `json:"received"`
+	Expected         float64 `json:"expected"`
+	Counted          float64 `json:"counted"`
+	TheoreticalUsage float64 `json:"theoretical_usage"`
+	ActualUsage      float64 `json:"actual_usage"`
+	// Variance is negative if less was counted than expected
+	Variance      float64  `json:"variance"`
+	VarianceValue float64  `json:"variance_value"`
+	Shrinkage     *float64 `json:"shrinkage"`
+	Flagged       bool     `json:"flagged"`
+}
+
+type StocktakeViewModel struct {
+	Stocktake     db.Stocktake               `json:"stocktake"`
+	Counts        []db.GetStocktakeCountsRow `json:"counts"`
+	Ingredients   map[int64]string           `json:"ingredients"`
+	Units         []db.Unit                  `json:"units"`
+	Threshold     float64                    `json:"threshold"`
+	Variance      []StocktakeVariance        `json:"variance"`
+	VarianceValue float64                    `json:"variance_value"`
+}
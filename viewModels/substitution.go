@@ -0,0 +1,21 @@
+package viewmodels
+
+type SubstitutionRow struct {
+	ProductID   int64    `json:"product_id"`
+	ProductName string   `json:"product_name"`
+	Direct      bool     `json:"direct"`
+	Via         []string `json:"via"`
+	CurrentCost float64  `json:"current_cost"`
+	NewCost     float64  `json:"new_cost"`
+}
+
+type SubstitutionViewModel struct {
+	FromID int64   `json:"from_id"`
+	ToID   int64   `json:"to_id"`
+	Ratio  float64 `json:"ratio"`
+	// Ingredients holds the names of all ingredients with a price
+	Ingredients map[int64]string  `json:"ingredients"`
+	Rows        []SubstitutionRow `json:"rows"`
+	// Applied is the number of usages rewritten, set after the swap is applied
+	Applied *int64 `json:"applied"`
+}
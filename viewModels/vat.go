@@ -0,0 +1,15 @@
+package viewmodels
+
+import "github.com/mike-jl/price_calc/db"
+
+type CategoryWithVat struct {
+	db.Category
+	DineInVat   float64 `json:"dine_in_vat"`
+	TakeawayVat float64 `json:"takeaway_vat"`
+}
+
+type VatRateWithPeriods struct {
+	VatRate db.VatRate         `json:"vat_rate"`
+	Periods []db.VatRatePeriod `json:"periods"`
+	Current *float64           `json:"current"`
+}
@@ -0,0 +1,21 @@
+package viewmodels
+
+import "github.com/mike-jl/price_calc/db"
+
+type WasteReasonTotal struct {
+	Reason  string  `json:"reason"`
+	Entries int     `json:"entries"`
+	Cost    float64 `json:"cost"`
+}
+
+type WasteViewModel struct {
+	From        int64              `json:"from"`
+	To          int64              `json:"to"`
+	Entries     []db.GetWasteRow   `json:"entries"`
+	ByReason    []WasteReasonTotal `json:"by_reason"`
+	Cost        float64            `json:"cost"`
+	Ingredients map[int64]string   `json:"ingredients"`
+	Products    map[int64]string   `json:"products"`
+	Units       []db.Unit          `json:"units"`
+	Reasons     []string           `json:"reasons"`
+}
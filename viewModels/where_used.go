@@ -0,0 +1,21 @@
+package viewmodels
+
+type WhereUsedRow struct {
+	ProductID   int64   `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	Quantity    float64 `json:"quantity"`
+	Unit        string  `json:"unit"`
+	Cost        float64 `json:"cost"`
+	// Share is the percentage of the product's ingredient cost
+	Share  float64 `json:"share"`
+	Direct bool    `json:"direct"`
+	// Via lists the base products the usage is reached through
+	Via []string `json:"via"`
+}
+
+type WhereUsed struct {
+	Name         string         `json:"name"`
+	IngredientID *int64         `json:"ingredient_id"`
+	ProductID    *int64         `json:"product_id"`
+	Rows         []WhereUsedRow `json:"rows"`
+}